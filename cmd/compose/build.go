@@ -35,15 +35,17 @@ import (
 
 type buildOptions struct {
 	*ProjectOptions
-	quiet   bool
-	pull    bool
-	push    bool
-	args    []string
-	noCache bool
-	memory  cliopts.MemBytes
-	ssh     string
-	builder string
-	deps    bool
+	quiet     bool
+	pull      bool
+	push      bool
+	args      []string
+	noCache   bool
+	forceRm   bool
+	memory    cliopts.MemBytes
+	ssh       string
+	builder   string
+	deps      bool
+	platforms []string
 }
 
 func (opts buildOptions) toAPIBuildOptions(services []string) (api.BuildOptions, error) {
@@ -68,16 +70,17 @@ func (opts buildOptions) toAPIBuildOptions(services []string) (api.BuildOptions,
 	}
 
 	return api.BuildOptions{
-		Pull:     opts.pull,
-		Push:     opts.push,
-		Progress: ui.Mode,
-		Args:     types.NewMappingWithEquals(opts.args),
-		NoCache:  opts.noCache,
-		Quiet:    opts.quiet,
-		Services: services,
-		Deps:     opts.deps,
-		SSHs:     SSHKeys,
-		Builder:  builderName,
+		Pull:        opts.pull,
+		Push:        opts.push,
+		Progress:    ui.Mode,
+		Args:        types.NewMappingWithEquals(opts.args),
+		NoCache:     opts.noCache,
+		ForceRemove: opts.forceRm,
+		Quiet:       opts.quiet,
+		Services:    services,
+		Deps:        opts.deps,
+		SSHs:        SSHKeys,
+		Builder:     builderName,
 	}, nil
 }
 
@@ -118,13 +121,13 @@ func buildCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 	flags.StringVar(&opts.ssh, "ssh", "", "Set SSH authentications used when building service images. (use 'default' for using your default SSH Agent)")
 	flags.StringVar(&opts.builder, "builder", "", "Set builder to use")
 	flags.BoolVar(&opts.deps, "with-dependencies", false, "Also build dependencies (transitively)")
+	flags.StringSliceVar(&opts.platforms, "platform", nil, "Set target platforms for the build, overriding services build.platforms")
 
 	flags.Bool("parallel", true, "Build images in parallel. DEPRECATED")
 	flags.MarkHidden("parallel") //nolint:errcheck
 	flags.Bool("compress", true, "Compress the build context using gzip. DEPRECATED")
 	flags.MarkHidden("compress") //nolint:errcheck
-	flags.Bool("force-rm", true, "Always remove intermediate containers. DEPRECATED")
-	flags.MarkHidden("force-rm") //nolint:errcheck
+	flags.BoolVar(&opts.forceRm, "force-rm", false, "Always remove intermediate containers, even after unsuccessful builds (classic builder only)")
 	flags.BoolVar(&opts.noCache, "no-cache", false, "Do not use cache when building the image")
 	flags.Bool("no-rm", false, "Do not remove intermediate containers after a successful build. DEPRECATED")
 	flags.MarkHidden("no-rm") //nolint:errcheck
@@ -141,6 +144,10 @@ func runBuild(ctx context.Context, dockerCli command.Cli, backend api.Service, o
 		return err
 	}
 
+	if err := applyBuildPlatforms(project, opts.platforms); err != nil {
+		return err
+	}
+
 	if err := applyPlatforms(project, false); err != nil {
 		return err
 	}
@@ -30,6 +30,7 @@ import (
 	"github.com/compose-spec/compose-go/v2/cli"
 	"github.com/compose-spec/compose-go/v2/dotenv"
 	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/compose-spec/compose-go/v2/template"
 	"github.com/compose-spec/compose-go/v2/types"
 	composegoutils "github.com/compose-spec/compose-go/v2/utils"
 	"github.com/docker/buildx/util/logutil"
@@ -121,6 +122,7 @@ type ProjectOptions struct {
 	Compatibility bool
 	Progress      string
 	Offline       bool
+	StrictEnv     bool
 }
 
 // ProjectFunc does stuff within a types.Project
@@ -163,6 +165,8 @@ func (o *ProjectOptions) addProjectFlags(f *pflag.FlagSet) {
 	f.StringVar(&o.ProjectDir, "project-directory", "", "Specify an alternate working directory\n(default: the path of the, first specified, Compose file)")
 	f.StringVar(&o.WorkDir, "workdir", "", "DEPRECATED! USE --project-directory INSTEAD.\nSpecify an alternate working directory\n(default: the path of the, first specified, Compose file)")
 	f.BoolVar(&o.Compatibility, "compatibility", false, "Run compose in backward compatibility mode")
+	f.BoolVar(&o.StrictEnv, "strict-env", false, "Fail when a variable used in Compose files is not set")
+	f.BoolVar(&o.Offline, "offline", false, "Don't access the network, only use local images and build cache")
 	f.StringVar(&o.Progress, "progress", string(buildkit.AutoMode), fmt.Sprintf(`Set type of progress output (%s)`, strings.Join(printerModes, ", ")))
 	_ = f.MarkHidden("workdir")
 }
@@ -305,6 +309,9 @@ func (o *ProjectOptions) remoteLoaders(dockerCli command.Cli) []loader.ResourceL
 }
 
 func (o *ProjectOptions) toProjectOptions(po ...cli.ProjectOptionsFn) (*cli.ProjectOptions, error) {
+	if o.StrictEnv {
+		po = append(po, cli.WithLoadOptions(withStrictInterpolation))
+	}
 	return cli.NewProjectOptions(o.ConfigPaths,
 		append(po,
 			cli.WithWorkingDirectory(o.ProjectDir),
@@ -317,6 +324,31 @@ func (o *ProjectOptions) toProjectOptions(po ...cli.ProjectOptionsFn) (*cli.Proj
 			cli.WithName(o.ProjectName))...)
 }
 
+// withStrictInterpolation makes interpolation fail on unset variables which have no default value
+func withStrictInterpolation(options *loader.Options) {
+	if options.Interpolate == nil {
+		return
+	}
+	options.Interpolate.Substitute = func(value string, mapping template.Mapping) (string, error) {
+		return template.SubstituteWithOptions(value, mapping, template.WithReplacementFunction(strictReplacement), template.WithoutLogging)
+	}
+}
+
+func strictReplacement(substring string, mapping template.Mapping, cfg *template.Config) (string, error) {
+	value, applied, err := template.DefaultReplacementAppliedFunc(substring, mapping, cfg)
+	if err != nil {
+		return value, err
+	}
+	if !applied {
+		name := strings.TrimLeft(substring, "${")
+		if i := strings.IndexFunc(name, func(r rune) bool { return r == '}' || r == ':' || r == '-' || r == '?' || r == '+' }); i >= 0 {
+			name = name[:i]
+		}
+		return "", fmt.Errorf("variable %q is not set", name)
+	}
+	return value, nil
+}
+
 // PluginName is the name of the plugin
 const PluginName = "compose"
 
@@ -408,33 +440,9 @@ func RootCommand(dockerCli command.Cli, backend api.Service) *cobra.Command { //
 				formatter.SetANSIMode(dockerCli, formatter.Never)
 			}
 
-			switch ansi {
-			case "never":
-				ui.Mode = ui.ModePlain
-			case "always":
-				ui.Mode = ui.ModeTTY
-			}
-
-			switch opts.Progress {
-			case ui.ModeAuto:
-				ui.Mode = ui.ModeAuto
-				if ansi == "never" {
-					ui.Mode = ui.ModePlain
-				}
-			case ui.ModeTTY:
-				if ansi == "never" {
-					return fmt.Errorf("can't use --progress tty while ANSI support is disabled")
-				}
-				ui.Mode = ui.ModeTTY
-			case ui.ModePlain:
-				if ansi == "always" {
-					return fmt.Errorf("can't use --progress plain while ANSI support is forced")
-				}
-				ui.Mode = ui.ModePlain
-			case ui.ModeQuiet, "none":
-				ui.Mode = ui.ModeQuiet
-			default:
-				return fmt.Errorf("unsupported --progress value %q", opts.Progress)
+			ui.Mode, err = progressMode(opts.Progress, ansi)
+			if err != nil {
+				return err
 			}
 
 			// (4) options validation / normalization
@@ -477,6 +485,9 @@ func RootCommand(dockerCli command.Cli, backend api.Service) *cobra.Command { //
 				logrus.Debugf("Limiting max concurrency to %d jobs", parallel)
 				backend.MaxConcurrency(parallel)
 			}
+			if opts.Offline {
+				backend.OfflineMode(true)
+			}
 
 			// (5) dry run detection
 			ctx, err = backend.DryRunMode(ctx, dryRun)
@@ -592,9 +603,37 @@ func setEnvWithDotEnv(prjOpts *ProjectOptions) error {
 	return nil
 }
 
+// progressMode selects the progress UI mode for --progress value, checking it is compatible with --ansi
+func progressMode(progress string, ansi string) (string, error) {
+	switch progress {
+	case ui.ModeAuto:
+		if ansi == "never" {
+			return ui.ModePlain, nil
+		}
+		return ui.ModeAuto, nil
+	case ui.ModeTTY:
+		if ansi == "never" {
+			return "", fmt.Errorf("can't use --progress tty while ANSI support is disabled")
+		}
+		return ui.ModeTTY, nil
+	case ui.ModePlain:
+		if ansi == "always" {
+			return "", fmt.Errorf("can't use --progress plain while ANSI support is forced")
+		}
+		return ui.ModePlain, nil
+	case ui.ModeJSON:
+		return ui.ModeJSON, nil
+	case ui.ModeQuiet, "none":
+		return ui.ModeQuiet, nil
+	default:
+		return "", fmt.Errorf("unsupported --progress value %q", progress)
+	}
+}
+
 var printerModes = []string{
 	ui.ModeAuto,
 	ui.ModeTTY,
 	ui.ModePlain,
 	ui.ModeQuiet,
+	ui.ModeJSON,
 }
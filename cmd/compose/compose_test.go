@@ -17,10 +17,15 @@
 package compose
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"gotest.tools/v3/assert"
+
+	ui "github.com/docker/compose/v2/pkg/progress"
 )
 
 func TestFilterServices(t *testing.T) {
@@ -53,3 +58,103 @@ func TestFilterServices(t *testing.T) {
 	_, err = p.GetService("zot")
 	assert.NilError(t, err)
 }
+
+func TestToProjectMergesOverrideAndExtends(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.yaml": `
+services:
+  base:
+    image: alpine
+    environment:
+      LEVEL: base
+`,
+		"compose.yaml": `
+services:
+  web:
+    extends:
+      file: base.yaml
+      service: base
+    command: serve
+`,
+		"compose.override.yaml": `
+services:
+  web:
+    environment:
+      DEBUG: "true"
+`,
+	}
+	for name, content := range files {
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	opts := ProjectOptions{ProjectName: "test", ProjectDir: dir, Offline: true}
+	project, _, err := opts.ToProject(context.Background(), nil, nil)
+	assert.NilError(t, err)
+
+	web, err := project.GetService("web")
+	assert.NilError(t, err)
+	assert.Equal(t, web.Image, "alpine")
+	assert.DeepEqual(t, web.Command, types.ShellCommand{"serve"})
+	assert.Equal(t, *web.Environment["LEVEL"], "base")
+	assert.Equal(t, *web.Environment["DEBUG"], "true")
+	assert.Equal(t, len(project.ComposeFiles), 2)
+}
+
+func TestToProjectStrictEnv(t *testing.T) {
+	dir := t.TempDir()
+	content := `
+services:
+  web:
+    image: ${IMAGE:-nginx}
+    environment:
+      TOKEN: ${UNSET_TOKEN}
+`
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte(content), 0o600))
+
+	opts := ProjectOptions{ProjectName: "test", ProjectDir: dir, Offline: true}
+	project, _, err := opts.ToProject(context.Background(), nil, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, project.Services["web"].Image, "nginx")
+
+	opts.StrictEnv = true
+	_, _, err = opts.ToProject(context.Background(), nil, nil)
+	assert.ErrorContains(t, err, `variable "UNSET_TOKEN" is not set`)
+}
+
+func TestProgressMode(t *testing.T) {
+	tests := []struct {
+		progress string
+		ansi     string
+		expected string
+		err      string
+	}{
+		{progress: "auto", ansi: "auto", expected: ui.ModeAuto},
+		{progress: "auto", ansi: "never", expected: ui.ModePlain},
+		{progress: "tty", ansi: "always", expected: ui.ModeTTY},
+		{progress: "tty", ansi: "never", err: "can't use --progress tty while ANSI support is disabled"},
+		{progress: "plain", ansi: "always", err: "can't use --progress plain while ANSI support is forced"},
+		{progress: "json", ansi: "auto", expected: ui.ModeJSON},
+		{progress: "json", ansi: "never", expected: ui.ModeJSON},
+		{progress: "none", ansi: "auto", expected: ui.ModeQuiet},
+		{progress: "fancy", ansi: "auto", err: `unsupported --progress value "fancy"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.progress+"/"+tt.ansi, func(t *testing.T) {
+			mode, err := progressMode(tt.progress, tt.ansi)
+			if tt.err != "" {
+				assert.Error(t, err, tt.err)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, mode, tt.expected)
+		})
+	}
+}
+
+func TestProgressFlagAdvertisedModes(t *testing.T) {
+	for _, mode := range printerModes {
+		_, err := progressMode(mode, "auto")
+		assert.NilError(t, err, mode)
+	}
+}
@@ -160,7 +160,15 @@ func runConfig(ctx context.Context, dockerCli command.Cli, opts configOptions, s
 		if err != nil {
 			return err
 		}
-		content, err = project.MarshalYAML()
+
+		if opts.resolveImageDigests {
+			project, err = project.WithImagesResolved(compose.ImageDigestResolver(ctx, dockerCli.ConfigFile(), dockerCli.Client()))
+			if err != nil {
+				return err
+			}
+		}
+
+		content, err = formatProject(project, opts.Format)
 		if err != nil {
 			return err
 		}
@@ -230,6 +238,17 @@ func formatModel(model map[string]any, format string) (content []byte, err error
 	return
 }
 
+func formatProject(project *types.Project, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		return json.MarshalIndent(project, "", "  ")
+	case "yaml":
+		return project.MarshalYAML()
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
 func runServices(ctx context.Context, dockerCli command.Cli, opts configOptions) error {
 	project, err := opts.ToProject(ctx, dockerCli, nil, cli.WithoutEnvironmentResolution)
 	if err != nil {
@@ -319,8 +338,13 @@ func runConfigImages(ctx context.Context, dockerCli command.Cli, opts configOpti
 	if err != nil {
 		return err
 	}
+	images := make([]string, 0, len(project.Services))
 	for _, s := range project.Services {
-		fmt.Fprintln(dockerCli.Out(), api.GetImageNameOrDefault(s, project.Name))
+		images = append(images, api.GetImageNameOrDefault(s, project.Name))
+	}
+	sort.Strings(images)
+	for _, image := range images {
+		fmt.Fprintln(dockerCli.Out(), image)
 	}
 	return nil
 }
@@ -0,0 +1,50 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestFormatProject(t *testing.T) {
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"web": {Name: "web", Image: "nginx"},
+		},
+	}
+
+	content, err := formatProject(project, "json")
+	assert.NilError(t, err)
+	var model map[string]any
+	assert.NilError(t, json.Unmarshal(content, &model))
+	assert.Equal(t, model["name"], "test")
+	services := model["services"].(map[string]any)
+	assert.Equal(t, services["web"].(map[string]any)["image"], "nginx")
+
+	content, err = formatProject(project, "yaml")
+	assert.NilError(t, err)
+	assert.Assert(t, len(content) > 0)
+	assert.Equal(t, content[0], byte('n'))
+
+	_, err = formatProject(project, "toml")
+	assert.ErrorContains(t, err, `unsupported format "toml"`)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/davecgh/go-spew/spew"
@@ -82,6 +83,26 @@ func TestRunCreate_NoBuild(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestRunCreate_ImagesTimeout(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+	backend := mocks.NewMockService(ctrl)
+	expected := defaultCreateOptions(true)
+	expected.ImagesTimeout = 30 * time.Second
+	backend.EXPECT().Create(
+		gomock.Eq(ctx),
+		pullPolicy(""),
+		deepEqual(expected),
+	)
+
+	createOpts := createOptions{
+		imagesTimeout: 30,
+	}
+	buildOpts := buildOptions{}
+	project := sampleProject()
+	err := runCreate(ctx, nil, backend, createOpts, buildOpts, project, nil)
+	require.NoError(t, err)
+}
+
 func sampleProject() *types.Project {
 	return &types.Project{
 		Name: "test",
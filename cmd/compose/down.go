@@ -29,6 +29,7 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/prompt"
 )
 
 type downOptions struct {
@@ -38,6 +39,7 @@ type downOptions struct {
 	timeout       int
 	volumes       bool
 	images        string
+	confirm       bool
 }
 
 func downCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
@@ -67,6 +69,7 @@ func downCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 	flags.IntVarP(&opts.timeout, "timeout", "t", 0, "Specify a shutdown timeout in seconds")
 	flags.BoolVarP(&opts.volumes, "volumes", "v", false, `Remove named volumes declared in the "volumes" section of the Compose file and anonymous volumes attached to containers`)
 	flags.StringVar(&opts.images, "rmi", "", `Remove images used by services. "local" remove only images that don't have a custom tag ("local"|"all")`)
+	flags.BoolVar(&opts.confirm, "confirm", false, `Ask for confirmation before removing volumes or images`)
 	flags.SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
 		if name == "volume" {
 			name = "volumes"
@@ -88,6 +91,9 @@ func runDown(ctx context.Context, dockerCli command.Cli, backend api.Service, op
 		timeoutValue := time.Duration(opts.timeout) * time.Second
 		timeout = &timeoutValue
 	}
+	if opts.confirm {
+		backend.UsePrompter(prompt.NewPrompt(dockerCli.In(), dockerCli.Out()))
+	}
 	return backend.Down(ctx, name, api.DownOptions{
 		RemoveOrphans: opts.removeOrphans,
 		Project:       project,
@@ -17,9 +17,8 @@
 package compose
 
 import (
-	"fmt"
-
 	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/compose/v2/pkg/api"
 	"github.com/docker/compose/v2/pkg/utils"
 )
 
@@ -33,7 +32,7 @@ func applyPlatforms(project *types.Project, buildForSinglePlatform bool) error {
 		// default platform only applies if the service doesn't specify
 		if defaultPlatform != "" && service.Platform == "" {
 			if len(service.Build.Platforms) > 0 && !utils.StringContains(service.Build.Platforms, defaultPlatform) {
-				return fmt.Errorf("service %q build.platforms does not support value set by DOCKER_DEFAULT_PLATFORM: %s", name, defaultPlatform)
+				return api.NewServiceError(name, api.ErrInvalidConfig, "service %q build.platforms does not support value set by DOCKER_DEFAULT_PLATFORM: %s", name, defaultPlatform)
 			}
 			service.Platform = defaultPlatform
 		}
@@ -41,7 +40,7 @@ func applyPlatforms(project *types.Project, buildForSinglePlatform bool) error {
 		if service.Platform != "" {
 			if len(service.Build.Platforms) > 0 {
 				if !utils.StringContains(service.Build.Platforms, service.Platform) {
-					return fmt.Errorf("service %q build configuration does not support platform: %s", name, service.Platform)
+					return api.NewServiceError(name, api.ErrInvalidConfig, "service %q build configuration does not support platform: %s", name, service.Platform)
 				}
 			}
 
@@ -72,3 +71,24 @@ func applyPlatforms(project *types.Project, buildForSinglePlatform bool) error {
 	}
 	return nil
 }
+
+// applyBuildPlatforms restricts services build to the platforms selected by user, which must be supported by
+// service build.platforms when declared
+func applyBuildPlatforms(project *types.Project, platforms []string) error {
+	if len(platforms) == 0 {
+		return nil
+	}
+	for name, service := range project.Services {
+		if service.Build == nil {
+			continue
+		}
+		for _, platform := range platforms {
+			if len(service.Build.Platforms) > 0 && !utils.StringContains(service.Build.Platforms, platform) {
+				return api.NewServiceError(name, api.ErrInvalidConfig, "service %q build.platforms does not support platform: %s", name, platform)
+			}
+		}
+		service.Build.Platforms = platforms
+		project.Services[name] = service
+	}
+	return nil
+}
@@ -128,3 +128,44 @@ func TestApplyPlatforms_UnsupportedPlatform(t *testing.T) {
 			`service "test" build.platforms does not support value set by DOCKER_DEFAULT_PLATFORM: commodore/64`)
 	})
 }
+
+func TestApplyBuildPlatforms(t *testing.T) {
+	makeProject := func() *types.Project {
+		return &types.Project{
+			Services: types.Services{
+				"test": {
+					Name:  "test",
+					Image: "foo",
+					Build: &types.BuildConfig{
+						Context: ".",
+						Platforms: []string{
+							"linux/amd64",
+							"linux/arm64",
+							"linux/arm/v7",
+						},
+					},
+				},
+				"db": {
+					Name:  "db",
+					Image: "bar",
+					Build: &types.BuildConfig{
+						Context: ".",
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("Subset", func(t *testing.T) {
+		project := makeProject()
+		require.NoError(t, applyBuildPlatforms(project, []string{"linux/amd64", "linux/arm64"}))
+		require.EqualValues(t, []string{"linux/amd64", "linux/arm64"}, project.Services["test"].Build.Platforms)
+		require.EqualValues(t, []string{"linux/amd64", "linux/arm64"}, project.Services["db"].Build.Platforms)
+	})
+
+	t.Run("UnsupportedPlatform", func(t *testing.T) {
+		project := makeProject()
+		require.EqualError(t, applyBuildPlatforms(project, []string{"linux/amd64", "linux/s390x"}),
+			`service "test" build.platforms does not support platform: linux/s390x`)
+	})
+}
@@ -102,7 +102,7 @@ func runPs(ctx context.Context, dockerCli command.Cli, backend api.Service, serv
 		if len(services) > 0 {
 			for _, service := range services {
 				if !utils.StringContains(names, service) {
-					return fmt.Errorf("no such service: %s", service)
+					return api.NewServiceError(service, api.ErrNotFound, "no such service: %s", service)
 				}
 			}
 		} else if !opts.Orphans {
@@ -72,16 +72,7 @@ func runScale(ctx context.Context, dockerCli command.Cli, backend api.Service, o
 		}
 	}
 
-	for key, value := range serviceReplicaTuples {
-		service, err := project.GetService(key)
-		if err != nil {
-			return err
-		}
-		service.SetScale(value)
-		project.Services[key] = service
-	}
-
-	return backend.Scale(ctx, project, api.ScaleOptions{Services: services})
+	return backend.Scale(ctx, project, api.ScaleOptions{Services: services, Replicas: serviceReplicaTuples})
 }
 
 func parseServicesReplicasArgs(args []string) (map[string]int, error) {
@@ -60,6 +60,12 @@ func runTop(ctx context.Context, dockerCli command.Cli, backend api.Service, opt
 	}
 
 	sort.Slice(containers, func(i, j int) bool {
+		if containers[i].Service != containers[j].Service {
+			return containers[i].Service < containers[j].Service
+		}
+		if containers[i].Replica != containers[j].Replica {
+			return containers[i].Replica < containers[j].Replica
+		}
 		return containers[i].Name < containers[j].Name
 	})
 
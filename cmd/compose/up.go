@@ -54,6 +54,7 @@ type upOptions struct {
 	timestamp          bool
 	wait               bool
 	waitTimeout        int
+	depsTimeout        int
 	watch              bool
 }
 
@@ -104,6 +105,8 @@ func upCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *c
 	flags.BoolVarP(&up.Detach, "detach", "d", false, "Detached mode: Run containers in the background")
 	flags.BoolVar(&create.Build, "build", false, "Build images before starting containers")
 	flags.BoolVar(&create.noBuild, "no-build", false, "Don't build an image, even if it's policy")
+	flags.BoolVar(&build.noCache, "no-cache", false, "Do not use cache when building images")
+	flags.BoolVar(&build.forceRm, "force-rm", false, "Always remove intermediate containers, even after unsuccessful builds (classic builder only)")
 	flags.StringVar(&create.Pull, "pull", "policy", `Pull image before running ("always"|"missing"|"never")`)
 	removeOrphans := utils.StringToBool(os.Getenv(ComposeRemoveOrphans))
 	flags.BoolVar(&create.removeOrphans, "remove-orphans", removeOrphans, "Remove containers for services not defined in the Compose file")
@@ -121,11 +124,13 @@ func upCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *c
 	flags.BoolVar(&create.recreateDeps, "always-recreate-deps", false, "Recreate dependent containers. Incompatible with --no-recreate.")
 	flags.BoolVarP(&create.noInherit, "renew-anon-volumes", "V", false, "Recreate anonymous volumes instead of retrieving data from the previous containers")
 	flags.BoolVar(&create.quietPull, "quiet-pull", false, "Pull without printing progress information")
+	flags.IntVar(&create.imagesTimeout, "images-timeout", 0, "Maximum duration in seconds to pull and build services images")
 	flags.StringArrayVar(&up.attach, "attach", []string{}, "Restrict attaching to the specified services. Incompatible with --attach-dependencies.")
 	flags.StringArrayVar(&up.noAttach, "no-attach", []string{}, "Do not attach (stream logs) to the specified services")
 	flags.BoolVar(&up.attachDependencies, "attach-dependencies", false, "Automatically attach to log output of dependent services")
 	flags.BoolVar(&up.wait, "wait", false, "Wait for services to be running|healthy. Implies detached mode.")
 	flags.IntVar(&up.waitTimeout, "wait-timeout", 0, "Maximum duration to wait for the project to be running|healthy")
+	flags.IntVar(&up.depsTimeout, "dependencies-timeout", 0, "Maximum duration in seconds to wait for depends_on conditions before starting a service")
 	flags.BoolVarP(&up.watch, "watch", "w", false, "Watch source code and rebuild/refresh containers when files are updated.")
 
 	return upCmd
@@ -206,6 +211,7 @@ func runUp(
 		Inherit:              !createOptions.noInherit,
 		Timeout:              createOptions.GetTimeout(),
 		QuietPull:            createOptions.quietPull,
+		ImagesTimeout:        time.Duration(createOptions.imagesTimeout) * time.Second,
 	}
 
 	if upOptions.noStart {
@@ -251,15 +257,16 @@ func runUp(
 	return backend.Up(ctx, project, api.UpOptions{
 		Create: create,
 		Start: api.StartOptions{
-			Project:      project,
-			Attach:       consumer,
-			AttachTo:     attach,
-			ExitCodeFrom: upOptions.exitCodeFrom,
-			CascadeStop:  upOptions.cascadeStop,
-			Wait:         upOptions.wait,
-			WaitTimeout:  timeout,
-			Watch:        upOptions.watch,
-			Services:     services,
+			Project:             project,
+			Attach:              consumer,
+			AttachTo:            attach,
+			ExitCodeFrom:        upOptions.exitCodeFrom,
+			CascadeStop:         upOptions.cascadeStop,
+			Wait:                upOptions.wait,
+			WaitTimeout:         timeout,
+			DependenciesTimeout: time.Duration(upOptions.depsTimeout) * time.Second,
+			Watch:               upOptions.watch,
+			Services:            services,
 		},
 	})
 }
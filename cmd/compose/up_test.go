@@ -49,3 +49,17 @@ func TestApplyScaleOpt(t *testing.T) {
 	assert.Equal(t, *bar.Deploy.Replicas, 3)
 
 }
+
+func TestUpBuildFlags(t *testing.T) {
+	cmd := upCommand(&ProjectOptions{}, nil, nil)
+	for _, name := range []string{"no-cache", "force-rm"} {
+		flag := cmd.Flags().Lookup(name)
+		assert.Assert(t, flag != nil, name)
+		assert.Check(t, !flag.Hidden, name)
+	}
+
+	opts, err := buildOptions{ProjectOptions: &ProjectOptions{}, noCache: true, forceRm: true}.toAPIBuildOptions(nil)
+	assert.NilError(t, err)
+	assert.Check(t, opts.NoCache)
+	assert.Check(t, opts.ForceRemove)
+}
@@ -166,6 +166,10 @@ func (c *ContainerContext) Service() string {
 	return c.c.Service
 }
 
+func (c *ContainerContext) Number() int {
+	return c.c.Number
+}
+
 func (c *ContainerContext) Project() string {
 	return c.c.Project
 }
@@ -18,12 +18,16 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/docker/compose/v2/pkg/utils"
+	"github.com/moby/buildkit/session"
 )
 
 // Service manages a compose project
@@ -80,6 +84,15 @@ type Service interface {
 	Images(ctx context.Context, projectName string, options ImagesOptions) ([]ImageSummary, error)
 	// MaxConcurrency defines upper limit for concurrent operations against engine API
 	MaxConcurrency(parallel int)
+	// UseBuilder replaces the default build backend (BuildKit or classic builder) to build and pull services images
+	UseBuilder(builder Builder)
+	// UseImageVerifier sets a verifier services images must pass before containers are created
+	UseImageVerifier(verifier ImageVerifier)
+	// OfflineMode prevents operations from accessing the network, so only local images and build cache are used
+	OfflineMode(offline bool)
+	// UsePrompter sets the prompter used to confirm destructive operations. Down only asks for confirmation
+	// once a prompter is set
+	UsePrompter(prompter Prompter)
 	// DryRunMode defines if dry run applies to the command
 	DryRunMode(ctx context.Context, dryRun bool) (context.Context, error)
 	// Watch services' development context and sync/notify/rebuild/restart on changes
@@ -90,10 +103,16 @@ type Service interface {
 	Wait(ctx context.Context, projectName string, options WaitOptions) (int64, error)
 	// Scale manages numbers of container instances running per service
 	Scale(ctx context.Context, project *types.Project, options ScaleOptions) error
+	// WarmBuildCache runs services builds to populate build cache, without producing images
+	WarmBuildCache(ctx context.Context, project *types.Project, options BuildOptions) error
+	// PruneImages removes images built for the project which are neither used by any of its containers nor by its services
+	PruneImages(ctx context.Context, project *types.Project) error
 }
 
 type ScaleOptions struct {
 	Services []string
+	// Replicas overrides the number of containers to run, indexed by service name
+	Replicas map[string]int
 }
 
 type WaitOptions struct {
@@ -129,14 +148,22 @@ type BuildOptions struct {
 	Pull bool
 	// Push pushes service images
 	Push bool
-	// Progress set type of progress output ("auto", "plain", "tty")
+	// Progress set type of progress output ("auto", "plain", "tty", "quiet", "json")
 	Progress string
+	// Out is where build progress is written to, defaults to os.Stdout
+	Out io.Writer
 	// Args set build-time args
 	Args types.MappingWithEquals
+	// Environment overrides variables used to resolve build args declared without a value
+	Environment types.Mapping
 	// NoCache disables cache use
 	NoCache bool
+	// ForceRemove always removes intermediate containers, even after unsuccessful builds (classic builder only)
+	ForceRemove bool
 	// Quiet make the build process not output to the console
 	Quiet bool
+	// Profiles activates services with matching profiles, other services are disabled
+	Profiles []string
 	// Services passed in the command line to be built
 	Services []string
 	// Deps also build selected services dependencies
@@ -147,6 +174,161 @@ type BuildOptions struct {
 	Memory int64
 	// Builder name passed in the command line
 	Builder string
+	// CheckCacheFrom warns when a registry referenced by cache_from can't be reached
+	CheckCacheFrom bool
+	// ExtraHosts set host-to-IP mappings added to all services builds, service-level entries take precedence
+	ExtraHosts types.HostsList
+	// PushPlatforms restricts pushed images to this subset of the built platforms
+	PushPlatforms []string
+	// Report is the path to write a JSON summary of the built service images
+	Report string
+	// Env lists host environment variables exposed to the build sandbox, as secrets with the same id
+	Env []string
+	// CheckBaseImages reports base images used by Dockerfiles for which registry has a newer digest
+	CheckBaseImages bool
+	// TempDir overrides the directory compose writes temporary files to while building. Images are pulled by the
+	// engine, which doesn't involve local temporary files
+	TempDir string
+	// Manifest is the path to a file recording services successfully built
+	Manifest string
+	// Resume skips services recorded by Manifest as already built with the same configuration
+	Resume bool
+	// LoadPlatform selects the platform of a multi-platform build to be loaded as service image
+	LoadPlatform string
+	// CacheOnly only populates build cache, no image is exported
+	CacheOnly bool
+	// InlineCache embeds build cache metadata in built images, so they can be used as cache_from sources
+	InlineCache bool
+	// NoCacheFilter disables cache for the listed build stages, without invalidating build args
+	NoCacheFilter []string
+	// ContextSizeWarning is the size, in bytes, above which a local build context (once ignore files applied)
+	// is reported as a warning. Disabled when not set
+	ContextSizeWarning int64
+	// Outputs overrides exporters for the build result, using buildx --output syntax (e.g. "type=oci,dest=app.tar"),
+	// indexed by service name. Such images are not loaded into the engine
+	Outputs map[string][]string
+	// EventsSocket is the path to a unix socket to stream build events to, as newline-delimited JSON
+	EventsSocket string
+	// EventsConn is a connection to stream build events to, and takes precedence over EventsSocket
+	EventsConn io.Writer
+	// EventsDrop discards build events when consumer can't keep up, rather than blocking build
+	EventsDrop bool
+	// AllowedRegistries restricts registries base images can be pulled from by Dockerfiles
+	AllowedRegistries []string
+	// IndexAnnotations are set on the image index pushed for multi-platform builds
+	IndexAnnotations map[string]string
+	// MediaTypes selects the media types used by multi-platform images, either MediaTypesOCI or MediaTypesDocker.
+	// Exporter default applies when not set
+	MediaTypes string
+	// HideCachedSteps suppresses progress output for build steps resolved from cache
+	HideCachedSteps bool
+	// DigestTag tags built images with an immutable tag derived from image digest
+	DigestTag bool
+	// Attachables are additional session providers attached to the build session. They replace
+	// providers of the same type compose would otherwise configure (e.g. auth)
+	Attachables []session.Attachable
+	// Provenance configures provenance attestation, e.g. "mode=max", or "false" to disable it
+	Provenance string
+	// SBOM configures SBOM attestation, e.g. "true" or "generator=docker/buildkit-syft-scanner"
+	SBOM string
+	// ProvenanceDir is the directory to write services provenance to, one file per service
+	ProvenanceDir string
+	// IgnoreFiles overrides the ignore file used to filter build context, indexed by service name
+	IgnoreFiles map[string]string
+	// SecretArgs sets build args from project secrets (file or environment based), indexed by build arg name.
+	// Values are read just before build, but still get recorded in image history
+	SecretArgs map[string]string
+	// Cancellation, if set, lets caller cancel individual services builds while others keep running
+	Cancellation *BuildCancellation
+}
+
+const (
+	// MediaTypesOCI uses OCI image index and manifests media types
+	MediaTypesOCI = "oci"
+	// MediaTypesDocker uses Docker v2 manifest list and manifests media types, for compatibility with older registries
+	MediaTypesDocker = "docker"
+)
+
+// ErrBuildCancelled is returned when a service build has been cancelled by BuildCancellation
+var ErrBuildCancelled = errors.New("build cancelled")
+
+// BuildCancellation is a handle to cancel builds of individual services during a Build
+type BuildCancellation struct {
+	mux     sync.Mutex
+	cancels map[string]context.CancelCauseFunc
+}
+
+// Cancel stops build for service. Returns false if service isn't being built
+func (c *BuildCancellation) Cancel(service string) bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	cancel, ok := c.cancels[service]
+	if ok {
+		cancel(ErrBuildCancelled)
+	}
+	return ok
+}
+
+// Context derives the context for service build, which Cancel can stop independently of the parent.
+// Returned func releases the context once build is complete
+func (c *BuildCancellation) Context(ctx context.Context, service string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.cancels == nil {
+		c.cancels = map[string]context.CancelCauseFunc{}
+	}
+	c.cancels[service] = cancel
+	return ctx, func() {
+		c.mux.Lock()
+		defer c.mux.Unlock()
+		delete(c.cancels, service)
+		cancel(nil)
+	}
+}
+
+// Builder is a build backend compose relies on to build and pull services images
+type Builder interface {
+	// Capabilities reports the build features supported by the backend
+	Capabilities() BuilderCapabilities
+	// BuildOne builds the image for a single service, and returns the built image ID
+	BuildOne(ctx context.Context, project *types.Project, service types.ServiceConfig, options BuildOptions) (string, error)
+	// PullOne pulls the image for a single service, for the selected platform if set, and returns the image ID
+	PullOne(ctx context.Context, service types.ServiceConfig, platform string) (string, error)
+}
+
+// ImageVerifier checks services images can be trusted, e.g. by checking their signature, before containers are created
+type ImageVerifier interface {
+	// Verify checks image, referenced by its repository digest, can be used by service. An error prevents service from running
+	Verify(ctx context.Context, service types.ServiceConfig, image string) error
+}
+
+// Prompter asks user for confirmation before a destructive operation
+type Prompter interface {
+	// Confirm returns true if user accepted message, defaultValue applies when user doesn't answer
+	Confirm(message string, defaultValue bool) (bool, error)
+}
+
+// BuilderCapabilities lists the build features a Builder supports
+type BuilderCapabilities struct {
+	// MultiPlatform builder can build an image for multiple platforms at once
+	MultiPlatform bool
+	// Secrets builder can expose secrets to the build
+	Secrets bool
+	// SSH builder can forward SSH agent or keys to the build
+	SSH bool
+	// AdditionalContexts builder supports named build contexts
+	AdditionalContexts bool
+	// Push builder pushes built images when BuildOptions.Push is set, restricted to BuildOptions.PushPlatforms if
+	// also MultiPlatform
+	Push bool
+	// Outputs builder exports build result as set by BuildOptions.Outputs
+	Outputs bool
+	// CacheOnly builder can populate build cache without exporting an image
+	CacheOnly bool
+	// Attestations builder attaches provenance attestation as set by BuildOptions.Provenance, and writes it into
+	// BuildOptions.ProvenanceDir
+	Attestations bool
 }
 
 // Apply mutates project according to build options
@@ -154,7 +336,7 @@ func (o BuildOptions) Apply(project *types.Project) error {
 	platform := project.Environment["DOCKER_DEFAULT_PLATFORM"]
 	for name, service := range project.Services {
 		if service.Image == "" && service.Build == nil {
-			return fmt.Errorf("invalid service %q. Must specify either image or build", name)
+			return NewServiceError(name, ErrInvalidConfig, "invalid service %q. Must specify either image or build", name)
 		}
 
 		if service.Build == nil {
@@ -162,13 +344,13 @@ func (o BuildOptions) Apply(project *types.Project) error {
 		}
 		if platform != "" {
 			if len(service.Build.Platforms) > 0 && !utils.StringContains(service.Build.Platforms, platform) {
-				return fmt.Errorf("service %q build.platforms does not support value set by DOCKER_DEFAULT_PLATFORM: %s", name, platform)
+				return NewServiceError(name, ErrInvalidConfig, "service %q build.platforms does not support value set by DOCKER_DEFAULT_PLATFORM: %s", name, platform)
 			}
 			service.Platform = platform
 		}
 		if service.Platform != "" {
 			if len(service.Build.Platforms) > 0 && !utils.StringContains(service.Build.Platforms, service.Platform) {
-				return fmt.Errorf("service %q build configuration does not support platform: %s", name, service.Platform)
+				return NewServiceError(name, ErrInvalidConfig, "service %q build configuration does not support platform: %s", name, service.Platform)
 			}
 		}
 
@@ -185,6 +367,8 @@ type CreateOptions struct {
 	Build *BuildOptions
 	// Services defines the services user interacts with
 	Services []string
+	// Profiles activates services with matching profiles, other services are disabled
+	Profiles []string
 	// Remove legacy containers for services that are not defined in the project
 	RemoveOrphans bool
 	// Ignore legacy containers for services that are not defined in the project
@@ -199,6 +383,19 @@ type CreateOptions struct {
 	Timeout *time.Duration
 	// QuietPull makes the pulling process quiet
 	QuietPull bool
+	// ImagesTimeout caps the time spent pulling and building services images, if set
+	ImagesTimeout time.Duration
+	// ImagesLock is a file to record the repository digest of images resolved for services
+	ImagesLock string
+	// VerifyImagesLock pulls services images by the digest recorded in ImagesLock, and makes create fail if
+	// services don't match the ones recorded
+	VerifyImagesLock bool
+	// PullRetries is the number of times pulling or inspecting images is retried after a transient failure,
+	// like a network error or registry being unavailable, with exponential backoff
+	PullRetries int
+	// PullRetryNotFound retries pulling images reported as not found for a short while, to cope with
+	// registries eventual consistency when an image has just been pushed
+	PullRetryNotFound bool
 }
 
 // StartOptions group options of the Start API
@@ -216,6 +413,8 @@ type StartOptions struct {
 	// Wait won't return until containers reached the running|healthy state
 	Wait        bool
 	WaitTimeout time.Duration
+	// DependenciesTimeout caps the time spent waiting for depends_on conditions before a service is started, if set
+	DependenciesTimeout time.Duration
 	// Services passed in the command line to be started
 	Services []string
 	Watch    bool
@@ -286,6 +485,14 @@ type PullOptions struct {
 	Quiet           bool
 	IgnoreFailures  bool
 	IgnoreBuildable bool
+	// RetryNotFound retries pulling images reported as not found for a short while, to cope with
+	// registries eventual consistency when an image has just been pushed
+	RetryNotFound bool
+	// Retries is the number of times pulling an image is retried after a transient failure, like a network
+	// error or registry being unavailable, with exponential backoff
+	Retries int
+	// Profiles activates services with matching profiles, other services are disabled
+	Profiles []string
 }
 
 // ImagesOptions group options of the Images API
@@ -361,6 +568,9 @@ type AttachOptions struct {
 type EventsOptions struct {
 	Services []string
 	Consumer func(event Event) error
+	// Since and Until select events by time, as a timestamp or a duration relative to now, e.g. "10m"
+	Since string
+	Until string
 }
 
 // Event is a container runtime event served by Events API
@@ -451,6 +661,7 @@ type ContainerSummary struct {
 	Command      string
 	Project      string
 	Service      string
+	Number       int
 	Created      int64
 	State        string
 	Status       string
@@ -498,6 +709,8 @@ func (p PortPublishers) Swap(i, j int) {
 type ContainerProcSummary struct {
 	ID        string
 	Name      string
+	Service   string
+	Replica   int
 	Processes [][]string
 	Titles    []string
 }
@@ -509,6 +722,48 @@ type ImageSummary struct {
 	Repository    string
 	Tag           string
 	Size          int64
+	Created       time.Time
+}
+
+// BuildReport summarizes images built for a project
+type BuildReport struct {
+	Project  string
+	Services []ServiceBuildReport
+}
+
+// ServiceBuildReport holds the result of a service image build
+type ServiceBuildReport struct {
+	Service string
+	Image   string
+	Digest  string
+	// SourceFile is the compose file declaring the service, if known
+	SourceFile string `json:",omitempty"`
+	// OutdatedBases lists base images for which registry has a newer digest than the local one
+	OutdatedBases []string `json:",omitempty"`
+	// DigestTag is the immutable tag derived from image digest, if requested
+	DigestTag string `json:",omitempty"`
+}
+
+const (
+	// BuildEventStart is emitted when service build starts
+	BuildEventStart = "start"
+	// BuildEventStep is emitted when a build step completes
+	BuildEventStep = "step"
+	// BuildEventFinish is emitted when service build completes
+	BuildEventFinish = "finish"
+	// BuildEventError is emitted when service build fails
+	BuildEventError = "error"
+	// BuildEventWarning is emitted when a potential issue is detected with service build, e.g. a large build context
+	BuildEventWarning = "warning"
+)
+
+// BuildEvent is a structured event emitted while building services
+type BuildEvent struct {
+	Service string
+	Type    string
+	Message string `json:",omitempty"`
+	Cached  bool   `json:",omitempty"`
+	Time    time.Time
 }
 
 // ServiceStatus hold status about a service
@@ -63,6 +63,7 @@ type DryRunClient struct {
 	containers []moby.Container
 	execs      sync.Map
 	resolver   *imagetools.Resolver
+	out        io.Writer
 }
 
 type execDetails struct {
@@ -85,6 +86,7 @@ func NewDryRunClient(apiClient client.APIClient, cli command.Cli) (*DryRunClient
 		containers: []moby.Container{},
 		execs:      sync.Map{},
 		resolver:   imagetools.New(configFile),
+		out:        cli.Out(),
 	}, nil
 }
 
@@ -204,8 +206,12 @@ func (d *DryRunClient) CopyToContainer(ctx context.Context, container, path stri
 }
 
 func (d *DryRunClient) ImageBuild(ctx context.Context, reader io.Reader, options moby.ImageBuildOptions) (moby.ImageBuildResponse, error) {
+	status := fmt.Sprintf("%sSuccessfully built: dryRunID\n", DRYRUN_PREFIX)
+	for _, tag := range options.Tags {
+		status += fmt.Sprintf("%sSuccessfully tagged: %s\n", DRYRUN_PREFIX, tag)
+	}
 	jsonMessage, err := json.Marshal(&jsonmessage.JSONMessage{
-		Status:   fmt.Sprintf("%[1]sSuccessfully built: dryRunID\n%[1]sSuccessfully tagged: %[2]s\n", DRYRUN_PREFIX, options.Tags[0]),
+		Status:   status,
 		Progress: &jsonmessage.JSONProgress{},
 		ID:       "",
 	})
@@ -261,6 +267,10 @@ func (d *DryRunClient) ImagePush(ctx context.Context, ref string, options moby.I
 	return rc, nil
 }
 
+func (d *DryRunClient) ImageTag(ctx context.Context, imageName, ref string) error {
+	return nil
+}
+
 func (d *DryRunClient) ImageRemove(ctx context.Context, imageName string, options moby.ImageRemoveOptions) ([]image.DeleteResponse, error) {
 	return nil, nil
 }
@@ -317,7 +327,11 @@ func (d *DryRunClient) ContainerExecStart(ctx context.Context, execID string, co
 		return fmt.Errorf("invalid exec ID %q", execID)
 	}
 	details := v.(execDetails)
-	fmt.Printf("%sExecuting command %q in %s (detached mode)\n", DRYRUN_PREFIX, details.command, details.container)
+	out := d.out
+	if out == nil {
+		out = io.Discard
+	}
+	fmt.Fprintf(out, "%sExecuting command %q in %s (detached mode)\n", DRYRUN_PREFIX, details.command, details.container)
 	return nil
 }
 
@@ -447,10 +461,6 @@ func (d *DryRunClient) ImageSave(ctx context.Context, images []string) (io.ReadC
 	return d.apiClient.ImageSave(ctx, images)
 }
 
-func (d *DryRunClient) ImageTag(ctx context.Context, imageName, ref string) error {
-	return d.apiClient.ImageTag(ctx, imageName, ref)
-}
-
 func (d *DryRunClient) ImagesPrune(ctx context.Context, pruneFilter filters.Args) (moby.ImagesPruneReport, error) {
 	return d.apiClient.ImagesPrune(ctx, pruneFilter)
 }
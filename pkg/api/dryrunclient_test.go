@@ -0,0 +1,65 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	moby "github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"gotest.tools/v3/assert"
+)
+
+func TestDryRunImageTag(t *testing.T) {
+	// no underlying client: any delegated call would panic
+	d := &DryRunClient{}
+	assert.NilError(t, d.ImageTag(context.Background(), "sha256:1234", "myproject-web:latest"))
+}
+
+func TestDryRunImageBuild(t *testing.T) {
+	d := &DryRunClient{}
+
+	response, err := d.ImageBuild(context.Background(), nil, moby.ImageBuildOptions{})
+	assert.NilError(t, err)
+	var msg jsonmessage.JSONMessage
+	assert.NilError(t, json.NewDecoder(response.Body).Decode(&msg))
+	assert.Assert(t, strings.Contains(msg.Status, "Successfully built"))
+	assert.Assert(t, !strings.Contains(msg.Status, "Successfully tagged"))
+
+	response, err = d.ImageBuild(context.Background(), nil, moby.ImageBuildOptions{Tags: []string{"web:latest", "web:1.0"}})
+	assert.NilError(t, err)
+	assert.NilError(t, json.NewDecoder(response.Body).Decode(&msg))
+	assert.Assert(t, strings.Contains(msg.Status, "Successfully tagged: web:latest"))
+	assert.Assert(t, strings.Contains(msg.Status, "Successfully tagged: web:1.0"))
+}
+
+func TestDryRunExecStartWritesToOut(t *testing.T) {
+	out := &bytes.Buffer{}
+	d := &DryRunClient{out: out}
+
+	exec, err := d.ContainerExecCreate(context.Background(), "web-1", moby.ExecConfig{Cmd: []string{"ls"}})
+	assert.NilError(t, err)
+	assert.NilError(t, d.ContainerExecStart(context.Background(), exec.ID, moby.ExecStartCheck{}))
+	assert.Assert(t, strings.Contains(out.String(), `Executing command ["ls"] in web-1`))
+
+	err = d.ContainerExecStart(context.Background(), exec.ID, moby.ExecStartCheck{})
+	assert.ErrorContains(t, err, "invalid exec ID")
+}
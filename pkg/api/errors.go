@@ -18,6 +18,7 @@ package api
 
 import (
 	"errors"
+	"fmt"
 )
 
 const (
@@ -51,8 +52,38 @@ var (
 	// ErrWrongContextType is returned when the caller tries to get a context
 	// with the wrong type
 	ErrWrongContextType = errors.New("wrong context type")
+	// ErrInvalidConfig is returned when a service configuration can't be used
+	// for the requested operation
+	ErrInvalidConfig = errors.New("invalid configuration")
 )
 
+// ServiceError is returned when an operation fails because of a specific service.
+// Category is one of the sentinel errors declared in this package, so callers can
+// tell failures apart without parsing the error string. It doesn't affect the
+// command exit code
+type ServiceError struct {
+	// Service is the name of the service the error relates to
+	Service string
+	// Category classifies the failure, e.g. ErrNotFound or ErrInvalidConfig
+	Category error
+	// Err describes the failure, as reported to user
+	Err error
+}
+
+// NewServiceError creates a ServiceError for service, with message formatted according to format
+func NewServiceError(service string, category error, format string, a ...any) error {
+	return &ServiceError{
+		Service:  service,
+		Category: category,
+		Err:      fmt.Errorf(format, a...),
+	}
+}
+
+func (e *ServiceError) Error() string { return e.Err.Error() }
+
+// Unwrap returns both category and underlying error, so errors.Is matches any of them
+func (e *ServiceError) Unwrap() []error { return []error{e.Category, e.Err} }
+
 // IsNotFoundError returns true if the unwrapped error is ErrNotFound
 func IsNotFoundError(err error) bool {
 	return errors.Is(err, ErrNotFound)
@@ -88,6 +119,11 @@ func IsErrParsingFailed(err error) bool {
 	return errors.Is(err, ErrParsingFailed)
 }
 
+// IsErrInvalidConfig returns true if the unwrapped error is ErrInvalidConfig
+func IsErrInvalidConfig(err error) bool {
+	return errors.Is(err, ErrInvalidConfig)
+}
+
 // IsErrCanceled returns true if the unwrapped error is ErrCanceled
 func IsErrCanceled(err error) bool {
 	return errors.Is(err, ErrCanceled)
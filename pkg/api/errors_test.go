@@ -51,3 +51,16 @@ func TestIsUnknown(t *testing.T) {
 
 	assert.Assert(t, !IsUnknownError(errors.New("another error")))
 }
+
+func TestServiceError(t *testing.T) {
+	cause := errors.New("signature mismatch")
+	err := fmt.Errorf("up: %w", NewServiceError("web", ErrForbidden, "image for service %q failed verification: %w", "web", cause))
+	assert.Error(t, err, `up: image for service "web" failed verification: signature mismatch`)
+	assert.Assert(t, IsForbiddenError(err))
+	assert.Assert(t, errors.Is(err, cause))
+	assert.Assert(t, !IsErrInvalidConfig(err))
+
+	var serviceErr *ServiceError
+	assert.Assert(t, errors.As(err, &serviceErr))
+	assert.Equal(t, serviceErr.Service, "web")
+}
@@ -22,16 +22,22 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-
-	"github.com/moby/buildkit/util/progress/progressui"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/containerd/containerd/platforms"
+	"github.com/distribution/reference"
 	"github.com/docker/buildx/build"
 	"github.com/docker/buildx/builder"
 	"github.com/docker/buildx/controller/pb"
 	"github.com/docker/buildx/store/storeutil"
 	"github.com/docker/buildx/util/buildflags"
+	"github.com/docker/buildx/util/imagetools"
 	xprogress "github.com/docker/buildx/util/progress"
 	"github.com/docker/cli/cli/command"
 	cliopts "github.com/docker/cli/opts"
@@ -39,7 +45,6 @@ import (
 	"github.com/docker/compose/v2/pkg/api"
 	"github.com/docker/compose/v2/pkg/progress"
 	"github.com/docker/compose/v2/pkg/utils"
-	"github.com/docker/docker/builder/remotecontext/urlutil"
 	"github.com/docker/go-units"
 	bclient "github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/session"
@@ -55,7 +60,11 @@ import (
 )
 
 func (s *composeService) Build(ctx context.Context, project *types.Project, options api.BuildOptions) error {
-	err := options.Apply(project)
+	project, err := withProfiles(project, options.Profiles)
+	if err != nil {
+		return err
+	}
+	err = options.Apply(project)
 	if err != nil {
 		return err
 	}
@@ -65,6 +74,12 @@ func (s *composeService) Build(ctx context.Context, project *types.Project, opti
 	}, s.stdinfo(), "Building")
 }
 
+func (s *composeService) WarmBuildCache(ctx context.Context, project *types.Project, options api.BuildOptions) error {
+	options.CacheOnly = true
+	options.Push = false
+	return s.Build(ctx, project, options)
+}
+
 type serviceToBuild struct {
 	name    string
 	service types.ServiceConfig
@@ -72,9 +87,20 @@ type serviceToBuild struct {
 
 //nolint:gocyclo
 func (s *composeService) build(ctx context.Context, project *types.Project, options api.BuildOptions, localImages map[string]string) (map[string]string, error) {
-	buildkitEnabled, err := s.dockerCli.BuildKitEnabled()
-	if err != nil {
-		return nil, err
+	// custom builder replaces BuildKit
+	buildkitEnabled := false
+	if s.builder == nil {
+		var err error
+		buildkitEnabled, err = s.dockerCli.BuildKitEnabled()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if s.offline {
+		if err := checkOfflineBuild(options); err != nil {
+			return nil, err
+		}
 	}
 
 	imageIDs := map[string]string{}
@@ -84,7 +110,7 @@ func (s *composeService) build(ctx context.Context, project *types.Project, opti
 	if options.Deps {
 		policy = types.IncludeDependencies
 	}
-	err = project.ForEachService(options.Services, func(serviceName string, service *types.ServiceConfig) error {
+	err := project.ForEachService(options.Services, func(serviceName string, service *types.ServiceConfig) error {
 		if service.Build == nil {
 			return nil
 		}
@@ -93,18 +119,48 @@ func (s *composeService) build(ctx context.Context, project *types.Project, opti
 		if localImagePresent && service.PullPolicy != types.PullPolicyBuild {
 			return nil
 		}
-		serviceToBeBuild[serviceName] = serviceToBuild{name: serviceName, service: *service}
+		toBuild := *service
+		if s.offline {
+			build, err := offlineBuildConfig(*service.Build)
+			if err != nil {
+				return err
+			}
+			toBuild.Build = &build
+		}
+		serviceToBeBuild[serviceName] = serviceToBuild{name: serviceName, service: toBuild}
 		return nil
 	}, policy)
 	if err != nil || len(serviceToBeBuild) == 0 {
 		return imageIDs, err
 	}
 
+	var manifest *buildManifest
+	if options.Manifest != "" {
+		manifest, err = loadBuildManifest(options.Manifest)
+		if err != nil {
+			return nil, err
+		}
+		if !options.Resume {
+			manifest.Services = map[string]buildManifestEntry{}
+		}
+		if err := manifest.reconcile(project); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.TempDir != "" {
+		if err := checkTempDir(options.TempDir); err != nil {
+			return nil, err
+		}
+	}
+
 	// Initialize buildkit nodes
 	var (
 		b     *builder.Builder
 		nodes []builder.Node
 		w     *xprogress.Printer
+
+		provenanceFetcher descriptorFetcher
 	)
 	if buildkitEnabled {
 		builderName := options.Builder
@@ -120,6 +176,17 @@ func (s *composeService) build(ctx context.Context, project *types.Project, opti
 		if err != nil {
 			return nil, err
 		}
+		if err := checkBuilderNodes(b.Name, nodes); err != nil {
+			return nil, err
+		}
+
+		if options.ProvenanceDir != "" {
+			imageopt, err := b.ImageOpt()
+			if err != nil {
+				return nil, err
+			}
+			provenanceFetcher = imagetools.New(imageopt)
+		}
 
 		// Progress needs its own context that lives longer than the
 		// build one otherwise it won't read all the messages from
@@ -130,7 +197,7 @@ func (s *composeService) build(ctx context.Context, project *types.Project, opti
 		if options.Quiet {
 			options.Progress = progress.ModeQuiet
 		}
-		w, err = xprogress.NewPrinter(progressCtx, os.Stdout, progressui.DisplayMode(options.Progress),
+		w, err = xprogress.NewPrinter(progressCtx, progressOutput(options.Out), progressDisplayMode(options.Progress),
 			xprogress.WithDesc(
 				fmt.Sprintf("building with %q instance using %s driver", b.Name, b.Driver),
 				fmt.Sprintf("%s:%s", b.Driver, b.Name),
@@ -152,19 +219,131 @@ func (s *composeService) build(ctx context.Context, project *types.Project, opti
 		}
 		return -1
 	}
-	err = InDependencyOrder(ctx, project, func(ctx context.Context, name string) error {
-		serviceToBuild, ok := serviceToBeBuild[name]
-		if !ok {
+	var (
+		outdatedBases = map[string][]string{}
+		resumed       = map[string]string{}
+		sharedBuilds  map[string]*sharedContextBuild
+	)
+	if len(options.SecretArgs) > 0 {
+		args, err := secretBuildArgs(ctx, project, options.SecretArgs)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(args))
+		for name := range args {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(s.stderr(), "WARNING: build args %s are set from secrets, but build args values are visible in image history. Consider using build secrets instead\n",
+			strings.Join(names, ", "))
+		options.Args = make(types.MappingWithEquals).OverrideBy(options.Args).OverrideBy(args)
+	}
+
+	emitter, err := newBuildEventsEmitter(options)
+	if err != nil {
+		return nil, err
+	}
+	if emitter != nil {
+		defer emitter.Close() //nolint:errcheck
+	}
+
+	// checks run for all services before any build starts, as services sharing a build context get built at once
+	for _, name := range names {
+		if _, ok := serviceToBeBuild[name]; !ok {
+			continue
+		}
+		service := serviceToBeBuild[name].service
+		if options.Resume && manifest != nil {
+			if digest, ok := manifest.completed(service); ok {
+				resumed[name] = digest
+				continue
+			}
+		}
+		outdated, err := s.checkServiceBuild(ctx, project, service, options, buildkitEnabled, emitter)
+		if err != nil {
+			return nil, err
+		}
+		if options.CheckBaseImages {
+			outdatedBases[name] = outdated
+		}
+	}
+
+	// services consuming another service's image must wait for it to be built
+	dependencies := imageDependencies(project, serviceToBeBuild)
+	// services sharing a build would be cancelled together, so don't group them when caller needs per-service cancellation
+	if buildkitEnabled && s.builder == nil && options.Cancellation == nil {
+		toGroup := map[string]serviceToBuild{}
+		for name, service := range serviceToBeBuild {
+			if _, ok := resumed[name]; !ok {
+				toGroup[name] = service
+			}
+		}
+		sharedBuilds = sharedContexts(toGroup, options.IgnoreFiles, dependencies)
+	}
+
+	// shared builds run within their own session, so they don't depend on the first service requesting them
+	session, cancelSessions := context.WithCancel(ctx)
+	defer cancelSessions()
+	buildShared := func(ctx context.Context, services []string) (map[string]buildkitResult, error) {
+		opts := map[string]build.Options{}
+		var cleanups []func()
+		defer func() {
+			for _, cleanup := range cleanups {
+				cleanup()
+			}
+		}()
+		for _, n := range services {
+			o, cleanup, err := s.serviceBuildOptions(project, serviceToBeBuild[n].service, options)
+			if err != nil {
+				return nil, err
+			}
+			cleanups = append(cleanups, cleanup)
+			opts[n] = o
+		}
+		// BuildKit prefixes progress with service name, which events get attributed to
+		return s.doBuildBuildkitMulti(ctx, opts, serviceProgressWriter(w, "", options, emitter), nodes)
+	}
+
+	buildService := func(ctx context.Context, name string) error {
+		service := serviceToBeBuild[name].service
+
+		if digest, ok := resumed[name]; ok {
+			builtDigests[getServiceIndex(name)] = digest
+			return nil
+		}
+
+		if s.builder != nil {
+			id, err := s.buildWithBuilder(ctx, project, service, options)
+			if err != nil {
+				return err
+			}
+			if options.CacheOnly {
+				return nil
+			}
+			builtDigests[getServiceIndex(name)] = id
+			if manifest != nil {
+				return manifest.record(service, id)
+			}
 			return nil
 		}
-		service := serviceToBuild.service
 
 		if !buildkitEnabled {
+			if options.CacheOnly {
+				return fmt.Errorf("warming build cache requires BuildKit")
+			}
+			if _, ok := options.Outputs[name]; ok {
+				return fmt.Errorf("the classic builder doesn't support custom outputs, set DOCKER_BUILDKIT=1 to use BuildKit")
+			}
 			id, err := s.doBuildClassic(ctx, project, service, options)
 			if err != nil {
 				return err
 			}
 			builtDigests[getServiceIndex(name)] = id
+			if manifest != nil {
+				if err := manifest.record(service, id); err != nil {
+					return err
+				}
+			}
 
 			if options.Push {
 				return s.push(ctx, project, api.PushOptions{})
@@ -176,22 +355,106 @@ func (s *composeService) build(ctx context.Context, project *types.Project, opti
 			fmt.Fprintln(s.stderr(), "WARNING: --memory is not supported by BuildKit and will be ignored")
 		}
 
-		buildOptions, err := s.toBuildOptions(project, service, options)
+		buildOptions, cleanup, err := s.serviceBuildOptions(project, service, options)
 		if err != nil {
 			return err
 		}
+		defer cleanup()
 
-		digest, err := s.doBuildBuildkit(ctx, name, buildOptions, w, nodes)
+		pw := serviceProgressWriter(w, name, options, emitter)
+
+		var result buildkitResult
+		if shared, ok := sharedBuilds[name]; ok {
+			result, err = shared.build(ctx, session, name, buildShared)
+		} else {
+			var results map[string]buildkitResult
+			results, err = s.doBuildBuildkitMulti(ctx, map[string]build.Options{name: buildOptions}, pw, nodes)
+			result = results[name]
+		}
 		if err != nil {
 			return err
 		}
+		if options.CacheOnly {
+			return nil
+		}
+		if _, ok := options.Outputs[name]; ok {
+			// image has been exported elsewhere, engine doesn't know about it
+			return nil
+		}
+		digest := result.digest
 		builtDigests[getServiceIndex(name)] = digest
+		if options.ProvenanceDir != "" && options.Provenance != "" {
+			if provenanceFetcher == nil || !options.Push || service.Image == "" {
+				// attestations are only kept by registry, image store drops them
+				fmt.Fprintf(s.stderr(), "WARNING: provenance attestation for service %q can only be read once pushed to a registry\n", name)
+			} else if err := writeProvenance(ctx, provenanceFetcher, options.ProvenanceDir, name, result.exporterResponse); err != nil {
+				return err
+			}
+		}
+		if manifest != nil {
+			if err := manifest.record(service, digest); err != nil {
+				return err
+			}
+		}
+
+		if options.Push && service.Image != "" && len(options.PushPlatforms) > 0 && len(buildOptions.Platforms) > 0 {
+			pushOptions, err := toPushPlatformsOptions(buildOptions, options.PushPlatforms)
+			if err != nil {
+				return err
+			}
+			// build cache makes this second pass a cheap export of the selected platforms
+			if _, err := s.doBuildBuildkit(ctx, name, pushOptions, pw, nodes); err != nil {
+				return err
+			}
+		}
 
 		return nil
+	}
+
+	var (
+		cancelled    []string
+		cancelledMux sync.Mutex
+	)
+	buildOrder := withImageDependencies(project, dependencies)
+	err = InDependencyOrder(ctx, buildOrder, func(ctx context.Context, name string) error {
+		if _, ok := serviceToBeBuild[name]; !ok {
+			return nil
+		}
+		if options.Cancellation != nil {
+			var release func()
+			ctx, release = options.Cancellation.Context(ctx, name)
+			defer release()
+		}
+		if emitter != nil {
+			emitter.emit(name, api.BuildEventStart, "")
+		}
+		err := buildService(ctx, name)
+		if emitter != nil {
+			if err != nil {
+				emitter.emit(name, api.BuildEventError, err.Error())
+			} else {
+				emitter.emit(name, api.BuildEventFinish, builtDigests[getServiceIndex(name)])
+			}
+		}
+		if err == nil {
+			imageResolved(ctx, name)
+		}
+		if err != nil && errors.Is(context.Cause(ctx), api.ErrBuildCancelled) {
+			// don't fail the whole build, so other services keep building
+			cancelledMux.Lock()
+			cancelled = append(cancelled, name)
+			cancelledMux.Unlock()
+			return nil
+		}
+		return err
 	}, func(traversal *graphTraversal) {
 		traversal.maxConcurrency = s.maxConcurrency
 	})
 
+	// services which failed or were cancelled might leave a shared build running
+	cancelSessions()
+	waitSharedBuilds(sharedBuilds)
+
 	// enforce all build event get consumed
 	if buildkitEnabled {
 		if errw := w.Wait(); errw != nil {
@@ -202,41 +465,174 @@ func (s *composeService) build(ctx context.Context, project *types.Project, opti
 	if err != nil {
 		return nil, err
 	}
+	if len(cancelled) > 0 {
+		sort.Strings(cancelled)
+		return nil, fmt.Errorf("%w for service(s): %s", api.ErrBuildCancelled, strings.Join(cancelled, ", "))
+	}
 
+	serviceDigests := map[string]string{}
+	digestTags := map[string]string{}
 	for i, imageDigest := range builtDigests {
 		if imageDigest != "" {
-			imageRef := api.GetImageNameOrDefault(project.Services[names[i]], project.Name)
+			service := project.Services[names[i]]
+			imageRef := api.GetImageNameOrDefault(service, project.Name)
 			imageIDs[imageRef] = imageDigest
+			serviceDigests[names[i]] = imageDigest
+
+			// multi-platform images are not loaded into the engine, so can't be tagged
+			if options.DigestTag && len(service.Build.Platforms) <= 1 {
+				tag, err := s.tagWithDigest(ctx, imageRef, imageDigest)
+				if err != nil {
+					return nil, err
+				}
+				digestTags[names[i]] = tag
+			}
+		}
+	}
+
+	if options.Report != "" {
+		report := newBuildReport(ctx, project, serviceDigests)
+		for i, service := range report.Services {
+			report.Services[i].OutdatedBases = outdatedBases[service.Service]
+			report.Services[i].DigestTag = digestTags[service.Service]
+		}
+		if err := writeBuildReport(options.Report, report); err != nil {
+			return nil, err
 		}
 	}
 	return imageIDs, err
 }
 
-func (s *composeService) ensureImagesExists(ctx context.Context, project *types.Project, buildOpts *api.BuildOptions, quietPull bool) error {
+// checkServiceBuild runs the checks requested by options against service build before it starts, and returns
+// the outdated base images when CheckBaseImages is set
+func (s *composeService) checkServiceBuild(ctx context.Context, project *types.Project, service types.ServiceConfig, options api.BuildOptions, buildkitEnabled bool, emitter *buildEventsEmitter) ([]string, error) {
+	name := service.Name
+	if s.builder != nil {
+		// options custom builder doesn't support would otherwise be silently ignored
+		if err := checkBuilderCapabilities(service, options, s.builder.Capabilities()); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(options.AllowedRegistries) > 0 {
+		args := resolveAndMergeBuildArgs(s.dockerCli, project, service, options)
+		if err := checkBaseImagesRegistries(service, args, options.AllowedRegistries); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.ContextSizeWarning > 0 {
+		size, exceeds, err := exceedsContextSize(service, options.IgnoreFiles[name], options.ContextSizeWarning)
+		if err != nil {
+			return nil, err
+		}
+		if exceeds {
+			msg := fmt.Sprintf("build context for service %q is %s, consider excluding files with a .dockerignore",
+				name, units.HumanSize(float64(size)))
+			fmt.Fprintf(s.stderr(), "WARNING: %s\n", msg)
+			if emitter != nil {
+				emitter.emit(name, api.BuildEventWarning, msg)
+			}
+		}
+	}
+
+	var outdated []string
+	if options.CheckBaseImages {
+		var err error
+		outdated, err = s.outdatedBaseImages(ctx, service)
+		if err != nil {
+			return nil, err
+		}
+		for _, base := range outdated {
+			fmt.Fprintf(s.stderr(), "WARNING: service %q base image %q is outdated, a newer version is available\n", name, base)
+		}
+	}
+
+	if options.CheckCacheFrom && buildkitEnabled && s.builder == nil {
+		if err := s.warnUnreachableCacheSources(ctx, service); err != nil {
+			return nil, err
+		}
+	}
+	return outdated, nil
+}
+
+// serviceBuildOptions converts service build into BuildKit options, applying the ignore file set for service.
+// Returned func removes temporary files and must be called once build completed
+func (s *composeService) serviceBuildOptions(project *types.Project, service types.ServiceConfig, options api.BuildOptions) (build.Options, func(), error) {
+	buildOptions, err := s.toBuildOptions(project, service, options)
+	if err != nil {
+		return build.Options{}, nil, err
+	}
+	cleanup := func() {}
+	if ignoreFile, ok := options.IgnoreFiles[service.Name]; ok {
+		buildOptions.Inputs, cleanup, err = withIgnoreFile(buildOptions.Inputs, service, ignoreFile, options.TempDir)
+		if err != nil {
+			return build.Options{}, nil, err
+		}
+	}
+	return buildOptions, cleanup, nil
+}
+
+// ensureImagesOptions group the settings used by ensureImagesExists to pull and build services images
+type ensureImagesOptions struct {
+	// Build, if set, allows images to be built
+	Build     *api.BuildOptions
+	QuietPull bool
+	// Timeout, if positive, caps the whole operation, cancelling in-flight pulls and builds once elapsed
+	Timeout time.Duration
+	// Retries is the number of times pulling or inspecting images is retried after a transient failure
+	Retries int
+	// RetryNotFound also retries pulling images reported as not found
+	RetryNotFound bool
+}
+
+// ensureImagesExists pulls and builds images for project services
+func (s *composeService) ensureImagesExists(ctx context.Context, project *types.Project, options ensureImagesOptions) (err error) {
 	for name, service := range project.Services {
 		if service.Image == "" && service.Build == nil {
-			return fmt.Errorf("invalid service %q. Must specify either image or build", name)
+			return api.NewServiceError(name, api.ErrInvalidConfig, "invalid service %q. Must specify either image or build", name)
+		}
+		if service.PullPolicy == types.PullPolicyBuild && service.Build == nil {
+			return api.NewServiceError(name, api.ErrInvalidConfig, "invalid service %q. pull_policy build requires a build section", name)
 		}
 	}
 
-	images, err := s.getLocalImagesDigests(ctx, project)
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	images, err := s.getLocalImagesDigests(ctx, project, options.Retries)
 	if err != nil {
 		return err
 	}
 
+	if options.Timeout > 0 {
+		var tracker *imagesTracker
+		ctx, tracker = withImagesTracker(ctx)
+		services := imagesToResolve(project, images, options.Build != nil)
+		defer func() {
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("timeout after %s waiting for images of service(s): %s: %w",
+					options.Timeout, strings.Join(tracker.unresolved(services), ", "), err)
+			}
+		}()
+	}
+
 	err = tracing.SpanWrapFunc("project/pull", tracing.ProjectOptions(ctx, project),
 		func(ctx context.Context) error {
-			return s.pullRequiredImages(ctx, project, images, quietPull)
+			return s.pullRequiredImages(ctx, project, images, options.QuietPull, options.Retries, options.RetryNotFound)
 		},
 	)(ctx)
 	if err != nil {
 		return err
 	}
 
-	if buildOpts != nil {
+	if options.Build != nil {
 		err = tracing.SpanWrapFunc("project/build", tracing.ProjectOptions(ctx, project),
 			func(ctx context.Context) error {
-				builtImages, err := s.build(ctx, project, *buildOpts, images)
+				builtImages, err := s.build(ctx, project, *options.Build, images)
 				if err != nil {
 					return err
 				}
@@ -252,6 +648,19 @@ func (s *composeService) ensureImagesExists(ctx context.Context, project *types.
 		}
 	}
 
+	for name, service := range project.Services {
+		image := api.GetImageNameOrDefault(service, project.Name)
+		if _, ok := images[image]; !ok && service.PullPolicy == types.PullPolicyNever {
+			return api.NewServiceError(name, api.ErrNotFound, "image %q for service %q is not available locally and pull_policy is never", image, name)
+		}
+	}
+
+	if s.verifier != nil {
+		if err := s.verifyImages(ctx, project); err != nil {
+			return err
+		}
+	}
+
 	// set digest as com.docker.compose.image label so we can detect outdated containers
 	for name, service := range project.Services {
 		image := api.GetImageNameOrDefault(service, project.Name)
@@ -267,7 +676,7 @@ func (s *composeService) ensureImagesExists(ctx context.Context, project *types.
 	return nil
 }
 
-func (s *composeService) getLocalImagesDigests(ctx context.Context, project *types.Project) (map[string]string, error) {
+func (s *composeService) getLocalImagesDigests(ctx context.Context, project *types.Project, retries int) (map[string]string, error) {
 	var imageNames []string
 	for _, s := range project.Services {
 		imgName := api.GetImageNameOrDefault(s, project.Name)
@@ -275,7 +684,9 @@ func (s *composeService) getLocalImagesDigests(ctx context.Context, project *typ
 			imageNames = append(imageNames, imgName)
 		}
 	}
-	imgs, err := s.getImages(ctx, imageNames)
+	imgs, err := withTransientRetries(ctx, retries, func() (map[string]api.ImageSummary, error) {
+		return s.getImages(ctx, imageNames)
+	}, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -290,8 +701,13 @@ func (s *composeService) getLocalImagesDigests(ctx context.Context, project *typ
 		if !ok {
 			continue
 		}
-		if service.Platform != "" {
-			platform, err := platforms.Parse(service.Platform)
+		// image is pulled for DOCKER_DEFAULT_PLATFORM when service doesn't set one, so it must match as well
+		requested := service.Platform
+		if requested == "" {
+			requested = project.Environment["DOCKER_DEFAULT_PLATFORM"]
+		}
+		if requested != "" {
+			platform, err := platforms.Parse(requested)
 			if err != nil {
 				return nil, err
 			}
@@ -309,11 +725,11 @@ func (s *composeService) getLocalImagesDigests(ctx context.Context, project *typ
 				// pretend it doesn't exist so that we can pull/build an image
 				// for the correct platform instead
 				delete(images, imgName)
+				continue
 			}
 		}
 
 		project.Services[i].CustomLabels.Add(api.ImageDigestLabel, digest)
-
 	}
 
 	return images, nil
@@ -324,7 +740,9 @@ func (s *composeService) getLocalImagesDigests(ctx context.Context, project *typ
 // First, args directly defined via `build.args` in YAML are considered.
 // Then, any explicitly passed args in opts (e.g. via `--build-arg` on the CLI) are merged, overwriting any
 // keys that already exist.
-// Next, any keys without a value are resolved using the project environment.
+// Next, any keys without a value are resolved using opts.Environment, then the project environment. As loaded by
+// compose-go, project environment combines `.env` file(s) with host environment, the latter taking precedence.
+// A project created without environment falls back to the host environment.
 //
 // Finally, standard proxy variables based on the Docker client configuration are added, but will not overwrite
 // any values if already present.
@@ -337,7 +755,7 @@ func resolveAndMergeBuildArgs(
 	result := make(types.MappingWithEquals).
 		OverrideBy(service.Build.Args).
 		OverrideBy(opts.Args).
-		Resolve(envResolver(project.Environment))
+		Resolve(envResolver(buildArgsEnvironment(project, opts)))
 
 	// proxy arguments do NOT override and should NOT have env resolution applied,
 	// so they're handled last
@@ -350,17 +768,25 @@ func resolveAndMergeBuildArgs(
 	return result
 }
 
-func (s *composeService) toBuildOptions(project *types.Project, service types.ServiceConfig, options api.BuildOptions) (build.Options, error) {
-	plats, err := parsePlatforms(service)
-	if err != nil {
-		return build.Options{}, err
+// buildArgsEnvironment returns the variables used to resolve build args declared without a value
+func buildArgsEnvironment(project *types.Project, opts api.BuildOptions) types.Mapping {
+	environment := project.Environment
+	if environment == nil {
+		environment = types.NewMapping(os.Environ())
+	}
+	if len(opts.Environment) == 0 {
+		return environment
 	}
+	return opts.Environment.Clone().Merge(environment)
+}
 
-	cacheFrom, err := buildflags.ParseCacheEntry(service.Build.CacheFrom)
+func (s *composeService) toBuildOptions(project *types.Project, service types.ServiceConfig, options api.BuildOptions) (build.Options, error) {
+	plats, err := buildPlatforms(service, options)
 	if err != nil {
 		return build.Options{}, err
 	}
-	cacheTo, err := buildflags.ParseCacheEntry(service.Build.CacheTo)
+
+	cacheFrom, cacheTo, err := buildCaches(project, service, options)
 	if err != nil {
 		return build.Options{}, err
 	}
@@ -369,21 +795,30 @@ func (s *composeService) toBuildOptions(project *types.Project, service types.Se
 		authprovider.NewDockerAuthProvider(s.configFile(), nil),
 	}
 	if len(options.SSHs) > 0 || len(service.Build.SSH) > 0 {
-		sshAgentProvider, err := sshAgentProvider(append(service.Build.SSH, options.SSHs...))
+		sshAgentProvider, err := sshAgentProvider(mergeSSHConfigs(service.Build.SSH, options.SSHs))
 		if err != nil {
 			return build.Options{}, err
 		}
 		sessionConfig = append(sessionConfig, sshAgentProvider)
 	}
 
-	if len(service.Build.Secrets) > 0 {
-		secretsProvider, err := addSecretsConfig(project, service)
+	if len(service.Build.Secrets) > 0 || len(options.Env) > 0 {
+		secretsProvider, err := addSecretsConfig(project, service, options.Env)
 		if err != nil {
 			return build.Options{}, err
 		}
 		sessionConfig = append(sessionConfig, secretsProvider)
 	}
 
+	sessionConfig, err = mergeAttachables(sessionConfig, options.Attachables)
+	if err != nil {
+		return build.Options{}, err
+	}
+
+	if err := checkAdditionalContexts(project, service.Build.AdditionalContexts); err != nil {
+		return build.Options{}, err
+	}
+
 	tags := []string{api.GetImageNameOrDefault(service, project.Name)}
 	if len(service.Build.Tags) > 0 {
 		tags = append(tags, service.Build.Tags...)
@@ -395,21 +830,14 @@ func (s *composeService) toBuildOptions(project *types.Project, service types.Se
 
 	imageLabels := getImageBuildLabels(project, service)
 
-	push := options.Push && service.Image != ""
-	exports := []bclient.ExportEntry{{
-		Type: "docker",
-		Attrs: map[string]string{
-			"load": "true",
-			"push": fmt.Sprint(push),
-		},
-	}}
-	if len(service.Build.Platforms) > 1 {
-		exports = []bclient.ExportEntry{{
-			Type: "image",
-			Attrs: map[string]string{
-				"push": fmt.Sprint(push),
-			},
-		}}
+	attests, err := buildAttests(options)
+	if err != nil {
+		return build.Options{}, err
+	}
+
+	exports, err := buildExports(service, options, plats)
+	if err != nil {
+		return build.Options{}, err
 	}
 
 	sp, err := build.ReadSourcePolicy()
@@ -422,27 +850,265 @@ func (s *composeService) toBuildOptions(project *types.Project, service types.Se
 			ContextPath:      service.Build.Context,
 			DockerfileInline: service.Build.DockerfileInline,
 			DockerfilePath:   dockerFilePath(service.Build.Context, service.Build.Dockerfile),
-			NamedContexts:    toBuildContexts(service.Build.AdditionalContexts),
+			NamedContexts:    toBuildContexts(project, service.Build.AdditionalContexts),
 		},
-		CacheFrom:    pb.CreateCaches(cacheFrom),
-		CacheTo:      pb.CreateCaches(cacheTo),
-		NoCache:      service.Build.NoCache,
-		Pull:         service.Build.Pull,
-		BuildArgs:    flatten(resolveAndMergeBuildArgs(s.dockerCli, project, service, options)),
-		Tags:         tags,
-		Target:       service.Build.Target,
-		Exports:      exports,
-		Platforms:    plats,
-		Labels:       imageLabels,
-		NetworkMode:  service.Build.Network,
-		ExtraHosts:   service.Build.ExtraHosts.AsList(":"),
-		Ulimits:      toUlimitOpt(service.Build.Ulimits),
-		Session:      sessionConfig,
-		Allow:        allow,
-		SourcePolicy: sp,
+		CacheFrom:     cacheFrom,
+		CacheTo:       cacheTo,
+		NoCache:       service.Build.NoCache,
+		NoCacheFilter: options.NoCacheFilter,
+		Pull:          service.Build.Pull,
+		BuildArgs:     flatten(resolveAndMergeBuildArgs(s.dockerCli, project, service, options)),
+		Tags:          tags,
+		Target:        service.Build.Target,
+		Exports:       exports,
+		Platforms:     plats,
+		Labels:        imageLabels,
+		NetworkMode:   service.Build.Network,
+		ExtraHosts:    mergeExtraHosts(options.ExtraHosts, service.Build.ExtraHosts).AsList(":"),
+		ShmSize:       cliopts.MemBytes(service.Build.ShmSize),
+		Ulimits:       toUlimitOpt(service.Build.Ulimits),
+		Session:       sessionConfig,
+		Allow:         allow,
+		Attests:       attests,
+		SourcePolicy:  sp,
 	}, nil
 }
 
+// buildPlatforms returns the platforms service is built for. When LoadPlatform is set, only this one is built
+// out of a multi-platform build, so that image can be loaded by the docker exporter
+func buildPlatforms(service types.ServiceConfig, options api.BuildOptions) ([]specs.Platform, error) {
+	plats, err := parsePlatforms(service)
+	if err != nil {
+		return nil, err
+	}
+	if options.LoadPlatform != "" && len(plats) > 1 {
+		return selectPlatforms(plats, []string{options.LoadPlatform})
+	}
+	return plats, nil
+}
+
+// buildCaches returns the cache sources and destinations for service build
+func buildCaches(project *types.Project, service types.ServiceConfig, options api.BuildOptions) ([]bclient.CacheOptionsEntry, []bclient.CacheOptionsEntry, error) {
+	cacheFrom, err := buildflags.ParseCacheEntry(service.Build.CacheFrom)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid cache_from: %w", err)
+	}
+	cacheTo, err := buildflags.ParseCacheEntry(service.Build.CacheTo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid cache_to: %w", err)
+	}
+	resolveLocalCachePaths(cacheFrom, project.WorkingDir)
+	resolveLocalCachePaths(cacheTo, project.WorkingDir)
+	if options.InlineCache {
+		cacheTo = append(cacheTo, &pb.CacheOptionsEntry{Type: "inline"})
+	}
+	return pb.CreateCaches(cacheFrom), pb.CreateCaches(cacheTo), nil
+}
+
+// buildAttests returns the attestations requested by options
+func buildAttests(options api.BuildOptions) (map[string]*string, error) {
+	var attestations []string
+	if options.Provenance != "" {
+		attestations = append(attestations, buildflags.CanonicalizeAttest("provenance", options.Provenance))
+	}
+	if options.SBOM != "" {
+		attestations = append(attestations, buildflags.CanonicalizeAttest("sbom", options.SBOM))
+	}
+	if len(attestations) == 0 {
+		return nil, nil
+	}
+	parsed, err := buildflags.ParseAttests(attestations)
+	if err != nil {
+		return nil, err
+	}
+	return pb.CreateAttestations(parsed), nil
+}
+
+// buildExports returns the exporters for service build result. Image is loaded into the docker engine, unless
+// built for multiple platforms, custom outputs are set, or build only populates cache
+func buildExports(service types.ServiceConfig, options api.BuildOptions, plats []specs.Platform) ([]bclient.ExportEntry, error) {
+	for k := range options.IndexAnnotations {
+		if !annotationKeyRegexp.MatchString(k) {
+			return nil, fmt.Errorf("invalid index annotation key %q", k)
+		}
+	}
+	switch options.MediaTypes {
+	case "", api.MediaTypesOCI, api.MediaTypesDocker:
+	default:
+		return nil, fmt.Errorf("invalid media types %q, must be one of %s or %s", options.MediaTypes, api.MediaTypesOCI, api.MediaTypesDocker)
+	}
+
+	var outputs []bclient.ExportEntry
+	o, hasOutputs := options.Outputs[service.Name]
+	if hasOutputs {
+		entries, err := buildflags.ParseExports(o)
+		if err != nil {
+			return nil, err
+		}
+		outputs, err = pb.CreateExports(entries)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if options.CacheOnly {
+		// no exporter, build result is only kept in cache
+		return nil, nil
+	}
+	if hasOutputs {
+		return outputs, nil
+	}
+
+	// when only a subset of platforms is to be pushed, this happens as a distinct export once build completed
+	push := options.Push && service.Image != "" && (len(options.PushPlatforms) == 0 || len(plats) == 0)
+	if len(plats) <= 1 {
+		return []bclient.ExportEntry{{
+			Type: "docker",
+			Attrs: map[string]string{
+				"load": "true",
+				"push": fmt.Sprint(push),
+			},
+		}}, nil
+	}
+	attrs := map[string]string{
+		"push": fmt.Sprint(push),
+	}
+	for k, v := range options.IndexAnnotations {
+		attrs[indexAnnotationPrefix+k] = v
+	}
+	switch options.MediaTypes {
+	case api.MediaTypesOCI:
+		attrs[ociMediaTypesAttr] = "true"
+	case api.MediaTypesDocker:
+		attrs[ociMediaTypesAttr] = "false"
+	}
+	return []bclient.ExportEntry{{
+		Type:  "image",
+		Attrs: attrs,
+	}}, nil
+}
+
+// checkAdditionalContexts makes sure named contexts set for service build can be resolved
+func checkAdditionalContexts(project *types.Project, contexts types.Mapping) error {
+	names := make([]string, 0, len(contexts))
+	for name := range contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		context := contexts[name]
+		if dep, ok := contextService(context); ok {
+			if _, ok := project.Services[dep]; !ok {
+				return fmt.Errorf("additional context %q refers to undefined service %q", name, dep)
+			}
+			continue
+		}
+		if image, ok := strings.CutPrefix(context, "docker-image://"); ok {
+			if _, err := reference.ParseNormalizedNamed(image); err != nil {
+				return fmt.Errorf("additional context %q has invalid image reference %q: %w", name, image, err)
+			}
+			continue
+		}
+		if filepath.IsAbs(context) {
+			if _, err := os.Stat(context); err != nil {
+				return fmt.Errorf("additional context %q can't be accessed: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// toPushPlatformsOptions derives build options to push a manifest list only including the selected platforms,
+// which must be a subset of the platforms opts builds for
+func toPushPlatformsOptions(opts build.Options, pushPlatforms []string) (build.Options, error) {
+	selected, err := selectPlatforms(opts.Platforms, pushPlatforms)
+	if err != nil {
+		return build.Options{}, err
+	}
+	attrs := map[string]string{
+		"push": "true",
+	}
+	for _, export := range opts.Exports {
+		for k, v := range export.Attrs {
+			if strings.HasPrefix(k, indexAnnotationPrefix) || k == ociMediaTypesAttr {
+				attrs[k] = v
+			}
+		}
+	}
+	opts.Platforms = selected
+	opts.Exports = []bclient.ExportEntry{{
+		Type:  "image",
+		Attrs: attrs,
+	}}
+	return opts, nil
+}
+
+// indexAnnotationPrefix is used by BuildKit image exporter to set annotations on image index
+const indexAnnotationPrefix = "annotation-index."
+
+// ociMediaTypesAttr selects OCI or Docker media types for BuildKit image exporter
+const ociMediaTypesAttr = "oci-mediatypes"
+
+var annotationKeyRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._/-]*$`)
+
+// selectPlatforms returns the built platforms matching the requested ones, and fails if a
+// requested platform isn't built
+func selectPlatforms(built []specs.Platform, requested []string) ([]specs.Platform, error) {
+	var selected []specs.Platform
+	for _, p := range requested {
+		platform, err := platforms.Parse(p)
+		if err != nil {
+			return nil, err
+		}
+		matcher := platforms.NewMatcher(platform)
+		found := false
+		for _, b := range built {
+			if matcher.Match(b) {
+				selected = append(selected, b)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("platform %q is not part of the built platforms", p)
+		}
+	}
+	return selected, nil
+}
+
+// mergeAttachables adds custom session providers to the ones configured by compose. A custom provider replaces
+// the one configured by compose with the same type, but custom providers can't conflict with each other.
+func mergeAttachables(attachables []session.Attachable, custom []session.Attachable) ([]session.Attachable, error) {
+	customTypes := map[reflect.Type]bool{}
+	for _, a := range custom {
+		t := reflect.TypeOf(a)
+		if customTypes[t] {
+			return nil, fmt.Errorf("conflicting session providers of type %s", t)
+		}
+		customTypes[t] = true
+	}
+	var merged []session.Attachable
+	for _, a := range attachables {
+		if !customTypes[reflect.TypeOf(a)] {
+			merged = append(merged, a)
+		}
+	}
+	return append(merged, custom...), nil
+}
+
+// mergeExtraHosts combines global and service-level extra hosts. When a host is declared by both,
+// service-level mapping wins.
+func mergeExtraHosts(global types.HostsList, service types.HostsList) types.HostsList {
+	merged := types.HostsList{}
+	for host, ips := range global {
+		merged[host] = ips
+	}
+	for host, ips := range service {
+		merged[host] = ips
+	}
+	return merged
+}
+
 func toUlimitOpt(ulimits map[string]*types.UlimitsConfig) *cliopts.UlimitOpt {
 	ref := map[string]*units.Ulimit{}
 	for _, limit := range toUlimits(ulimits) {
@@ -473,12 +1139,32 @@ func dockerFilePath(ctxName string, dockerfile string) string {
 	if dockerfile == "" {
 		return ""
 	}
-	if urlutil.IsGitURL(ctxName) || filepath.IsAbs(dockerfile) {
+	if isRemoteContext(ctxName) || isAbsPath(dockerfile) {
 		return dockerfile
 	}
 	return filepath.Join(ctxName, dockerfile)
 }
 
+// isRemoteContext reports whether build context is a git repository (including `ssh://` URLs and `#ref:subdir`
+// fragments) or a tarball URL, which BuildKit fetches itself rather than compose sending a local directory.
+// Like buildx does, an existing local directory wins over a path looking like a git URL
+func isRemoteContext(context string) bool {
+	if fi, err := os.Stat(context); err == nil && fi.IsDir() {
+		return false
+	}
+	return build.IsRemoteURL(context)
+}
+
+// isAbsPath reports whether path is absolute, also accepting Windows paths with a drive letter or UNC prefix
+// whatever the platform, as compose file might have been written for another one
+func isAbsPath(path string) bool {
+	if filepath.IsAbs(path) || strings.HasPrefix(path, `\\`) {
+		return true
+	}
+	return len(path) >= 3 && path[1] == ':' && (path[2] == '\\' || path[2] == '/') &&
+		('a' <= path[0] && path[0] <= 'z' || 'A' <= path[0] && path[0] <= 'Z')
+}
+
 func sshAgentProvider(sshKeys types.SSHConfig) (session.Attachable, error) {
 	sshConfig := make([]sshprovider.AgentConfig, 0, len(sshKeys))
 	for _, sshKey := range sshKeys {
@@ -490,8 +1176,49 @@ func sshAgentProvider(sshKeys types.SSHConfig) (session.Attachable, error) {
 	return sshprovider.NewSSHAgentProvider(sshConfig)
 }
 
-func addSecretsConfig(project *types.Project, service types.ServiceConfig) (session.Attachable, error) {
-	var sources []secretsprovider.Source
+// mergeSSHConfigs returns SSH keys declared by service, overridden by the ones with same ID set by user,
+// as BuildKit rejects duplicate IDs
+func mergeSSHConfigs(service types.SSHConfig, overrides types.SSHConfig) types.SSHConfig {
+	merged := make(types.SSHConfig, 0, len(service)+len(overrides))
+	for _, key := range service {
+		overridden := false
+		for _, o := range overrides {
+			if o.ID == key.ID {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			merged = append(merged, key)
+		}
+	}
+	return append(merged, overrides...)
+}
+
+func addSecretsConfig(project *types.Project, service types.ServiceConfig, env []string) (session.Attachable, error) {
+	sources, err := secretSources(project, service, env)
+	if err != nil {
+		return nil, err
+	}
+	for _, secret := range service.Build.Secrets {
+		if secret.UID != "" || secret.GID != "" || secret.Mode != nil {
+			logrus.Warn("secrets `uid`, `gid` and `mode` are not supported by BuildKit, they will be ignored")
+		}
+	}
+	store, err := secretsprovider.NewStore(sources)
+	if err != nil {
+		return nil, err
+	}
+	return secretsprovider.NewSecretProvider(store), nil
+}
+
+// secretSources returns the secrets exposed to service build, either declared by service or passed through
+// from host environment
+func secretSources(project *types.Project, service types.ServiceConfig, env []string) ([]secretsprovider.Source, error) {
+	sources, err := envPassthroughSources(env)
+	if err != nil {
+		return nil, err
+	}
 	for _, secret := range service.Build.Secrets {
 		config := project.Secrets[secret.Source]
 		id := secret.Source
@@ -512,15 +1239,26 @@ func addSecretsConfig(project *types.Project, service types.ServiceConfig) (sess
 		default:
 			return nil, fmt.Errorf("build.secrets only supports environment or file-based secrets: %q", secret.Source)
 		}
-		if secret.UID != "" || secret.GID != "" || secret.Mode != nil {
-			logrus.Warn("secrets `uid`, `gid` and `mode` are not supported by BuildKit, they will be ignored")
-		}
 	}
-	store, err := secretsprovider.NewStore(sources)
-	if err != nil {
-		return nil, err
+	return sources, nil
+}
+
+var envNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// envPassthroughSources exposes host environment variables to the build sandbox as secrets, so they can be
+// consumed by `RUN --mount=type=secret,id=NAME,env=NAME` without being persisted as build args in image history
+func envPassthroughSources(env []string) ([]secretsprovider.Source, error) {
+	var sources []secretsprovider.Source
+	for _, name := range env {
+		if !envNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid environment variable name %q", name)
+		}
+		sources = append(sources, secretsprovider.Source{
+			ID:  name,
+			Env: name,
+		})
 	}
-	return secretsprovider.NewSecretProvider(store), nil
+	return sources, nil
 }
 
 func getImageBuildLabels(project *types.Project, service types.ServiceConfig) types.Labels {
@@ -537,9 +1275,15 @@ func getImageBuildLabels(project *types.Project, service types.ServiceConfig) ty
 	return ret
 }
 
-func toBuildContexts(additionalContexts types.Mapping) map[string]build.NamedContext {
+func toBuildContexts(project *types.Project, additionalContexts types.Mapping) map[string]build.NamedContext {
 	namedContexts := map[string]build.NamedContext{}
 	for name, context := range additionalContexts {
+		if dep, ok := contextService(context); ok {
+			// service image has been built first, see imageDependencies
+			if service, ok := project.Services[dep]; ok {
+				context = "docker-image://" + api.GetImageNameOrDefault(service, project.Name)
+			}
+		}
 		namedContexts[name] = build.NamedContext{Path: context}
 	}
 	return namedContexts
@@ -0,0 +1,285 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+	"github.com/docker/docker/errdefs"
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/utils"
+)
+
+// outdatedBaseImages checks base images declared by service Dockerfile against registry, and
+// returns those for which a newer digest is available than the one present locally.
+// Base images which are not available locally are ignored, as build will get the latest one.
+func (s *composeService) outdatedBaseImages(ctx context.Context, service types.ServiceConfig) ([]string, error) {
+	dockerfile, err := readDockerfile(service.Build)
+	if err != nil || dockerfile == "" {
+		return nil, err
+	}
+	bases, _, err := dockerfileImages(dockerfile, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	var outdated []string
+	for _, base := range bases {
+		named, err := reference.ParseNormalizedNamed(base)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := named.(reference.Digested); ok {
+			// pinned by digest, user explicitly selected base image version
+			continue
+		}
+		named = reference.TagNameOnly(named)
+
+		inspect, _, err := s.apiClient().ImageInspectWithRaw(ctx, named.String())
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		auth, err := encodedAuth(named, s.configFile())
+		if err != nil {
+			return nil, err
+		}
+		distribution, err := s.apiClient().DistributionInspect(ctx, named.String(), auth)
+		if err != nil {
+			return nil, err
+		}
+
+		upToDate := false
+		for _, repoDigest := range inspect.RepoDigests {
+			if strings.HasSuffix(repoDigest, "@"+distribution.Descriptor.Digest.String()) {
+				upToDate = true
+				break
+			}
+		}
+		if !upToDate {
+			outdated = append(outdated, base)
+		}
+	}
+	return outdated, nil
+}
+
+// checkBaseImagesRegistries makes sure base images declared by service Dockerfile are pulled from allowed registries.
+// A base image which can't be resolved is rejected, as it could come from any registry
+func checkBaseImagesRegistries(service types.ServiceConfig, args types.MappingWithEquals, allowed []string) error {
+	if service.Build.DockerfileInline == "" && isRemoteContext(service.Build.Context) {
+		return api.NewServiceError(service.Name, api.ErrForbidden, "service %q uses a remote build context, base images can't be verified against allowed registries", service.Name)
+	}
+	dockerfile, err := readDockerfile(service.Build)
+	if err != nil {
+		return err
+	}
+	images, unresolved, err := dockerfileImages(dockerfile, args, true)
+	if err != nil {
+		return err
+	}
+	if len(unresolved) > 0 {
+		return api.NewServiceError(service.Name, api.ErrForbidden, "service %q base image %q can't be resolved, so can't be verified against allowed registries", service.Name, unresolved[0])
+	}
+	var bases []string
+	for _, image := range images {
+		if _, ok := service.Build.AdditionalContexts[image]; ok {
+			// replaced by a named context, which is checked on its own
+			continue
+		}
+		bases = append(bases, image)
+	}
+	var contexts []string
+	for _, context := range service.Build.AdditionalContexts {
+		if image, ok := strings.CutPrefix(context, "docker-image://"); ok {
+			contexts = append(contexts, image)
+		}
+	}
+	sort.Strings(contexts)
+	bases = append(bases, contexts...)
+	for _, base := range bases {
+		named, err := reference.ParseNormalizedNamed(base)
+		if err != nil {
+			return err
+		}
+		domain := reference.Domain(named)
+		if !utils.StringContains(allowed, domain) {
+			return api.NewServiceError(service.Name, api.ErrForbidden, "service %q base image %q uses registry %q which is not allowed", service.Name, base, domain)
+		}
+	}
+	return nil
+}
+
+func readDockerfile(build *types.BuildConfig) (string, error) {
+	if build.DockerfileInline != "" {
+		return build.DockerfileInline, nil
+	}
+	if isRemoteContext(build.Context) {
+		// remote build context, we can't inspect Dockerfile without fetching it
+		return "", nil
+	}
+	dockerfile := dockerFilePath(build.Context, build.Dockerfile)
+	if dockerfile == "" {
+		dockerfile = filepath.Join(build.Context, "Dockerfile")
+	}
+	content, err := os.ReadFile(dockerfile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Dockerfile: %w", err)
+	}
+	return string(content), nil
+}
+
+// dockerfileImages returns the external images a Dockerfile relies on with `FROM` instructions, and when sources is set
+// the ones `COPY --from` and `RUN --mount=from=` copy or mount files from. References to build stages and `scratch`
+// are ignored. Variables are expanded using the global `ARG` declared before first `FROM`, with their value set by args
+// or their default value, and images using a variable without a value are returned as unresolved
+func dockerfileImages(dockerfile string, args types.MappingWithEquals, sources bool) ([]string, []string, error) {
+	result, err := parser.Parse(strings.NewReader(dockerfile))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		images     []string
+		unresolved []string
+		globals    = map[string]string{}
+		// stages declared so far, which FROM can refer to
+		declared = map[string]bool{}
+		// all stages, which files can be copied or mounted from
+		stages = map[string]bool{}
+	)
+	for i, node := range fromInstructions(result.AST) {
+		stages[strconv.Itoa(i)] = true
+		if words := nodeWords(node); len(words) >= 3 && strings.EqualFold(words[1], "AS") {
+			stages[strings.ToLower(words[2])] = true
+		}
+	}
+	expand := func(value string) (string, bool) {
+		resolved := true
+		expanded := os.Expand(value, func(name string) string {
+			name, def, hasDefault := strings.Cut(name, ":-")
+			if v := globals[name]; v != "" {
+				return v
+			}
+			if hasDefault {
+				return def
+			}
+			resolved = false
+			return ""
+		})
+		return expanded, resolved
+	}
+	add := func(ref string, known map[string]bool) {
+		image, resolved := expand(ref)
+		switch {
+		case !resolved:
+			unresolved = append(unresolved, ref)
+		case image != "" && image != "scratch" && !known[strings.ToLower(image)]:
+			images = append(images, image)
+		}
+	}
+
+	stageIndex := 0
+	for _, node := range result.AST.Children {
+		switch node.Value {
+		case command.Arg:
+			if stageIndex > 0 {
+				// ARG declared within a build stage can't be used by FROM
+				continue
+			}
+			for _, arg := range nodeWords(node) {
+				name, value, hasValue := strings.Cut(arg, "=")
+				if v, ok := args[name]; ok && v != nil {
+					globals[name] = *v
+				} else if hasValue {
+					// default value can refer to previously declared ARG
+					if v, ok := expand(strings.Trim(value, `"'`)); ok {
+						globals[name] = v
+					}
+				}
+			}
+		case command.From:
+			words := nodeWords(node)
+			if len(words) == 0 {
+				stageIndex++
+				continue
+			}
+			add(words[0], declared)
+			declared[strconv.Itoa(stageIndex)] = true
+			if len(words) >= 3 && strings.EqualFold(words[1], "AS") {
+				declared[strings.ToLower(words[2])] = true
+			}
+			stageIndex++
+		case command.Copy:
+			if !sources {
+				continue
+			}
+			for _, flag := range node.Flags {
+				if from, ok := strings.CutPrefix(flag, "--from="); ok {
+					add(from, stages)
+				}
+			}
+		case command.Run:
+			if !sources {
+				continue
+			}
+			for _, flag := range node.Flags {
+				mount, ok := strings.CutPrefix(flag, "--mount=")
+				if !ok {
+					continue
+				}
+				for _, field := range strings.Split(mount, ",") {
+					if from, ok := strings.CutPrefix(field, "from="); ok {
+						add(from, stages)
+					}
+				}
+			}
+		}
+	}
+	return images, unresolved, nil
+}
+
+func fromInstructions(ast *parser.Node) []*parser.Node {
+	var from []*parser.Node
+	for _, node := range ast.Children {
+		if node.Value == command.From {
+			from = append(from, node)
+		}
+	}
+	return from
+}
+
+// nodeWords returns the arguments of a Dockerfile instruction
+func nodeWords(node *parser.Node) []string {
+	var words []string
+	for n := node.Next; n != nil; n = n.Next {
+		words = append(words, n.Value)
+	}
+	return words
+}
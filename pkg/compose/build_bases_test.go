@@ -0,0 +1,203 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/config/configfile"
+	moby "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	compose "github.com/docker/compose/v2/pkg/api"
+)
+
+func TestParseBaseImages(t *testing.T) {
+	bases, unresolved, err := dockerfileImages(`
+ARG GO_VERSION=1.21
+FROM --platform=$BUILDPLATFORM golang:${GO_VERSION} AS build
+FROM alpine:3.19 AS base
+from base AS dev
+FROM scratch
+FROM nginx:1.25
+COPY --from=registry.example.com/tools:1.0 /bin/tool /bin/tool
+`, nil, false)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, bases, []string{"golang:1.21", "alpine:3.19", "nginx:1.25"})
+	assert.Equal(t, len(unresolved), 0)
+}
+
+func TestOutdatedBaseImages(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	const (
+		upToDate = digest.Digest("sha256:2c7a8bd5d5ae1a9d3f1f1d8b8e9a5c67e3cf8a1f9bbd2c36e4e3d59ba1c1e3a1")
+		previous = digest.Digest("sha256:8b8e9a5c67e3cf8a1f9bbd2c36e4e3d59ba1c1e3a12c7a8bd5d5ae1a9d3f1f1d")
+		latest   = digest.Digest("sha256:9bbd2c36e4e3d59ba1c1e3a12c7a8bd5d5ae1a9d3f1f1d8b8e9a5c67e3cf8a1f")
+	)
+	api.EXPECT().ImageInspectWithRaw(gomock.Any(), "docker.io/library/alpine:3.19").
+		Return(moby.ImageInspect{RepoDigests: []string{"alpine@" + upToDate.String()}}, nil, nil)
+	api.EXPECT().DistributionInspect(gomock.Any(), "docker.io/library/alpine:3.19", gomock.Any()).
+		Return(registry.DistributionInspect{Descriptor: specs.Descriptor{Digest: upToDate}}, nil)
+	api.EXPECT().ImageInspectWithRaw(gomock.Any(), "docker.io/library/nginx:1.25").
+		Return(moby.ImageInspect{RepoDigests: []string{"nginx@" + previous.String()}}, nil, nil)
+	api.EXPECT().DistributionInspect(gomock.Any(), "docker.io/library/nginx:1.25", gomock.Any()).
+		Return(registry.DistributionInspect{Descriptor: specs.Descriptor{Digest: latest}}, nil)
+
+	service := types.ServiceConfig{
+		Name: "test",
+		Build: &types.BuildConfig{
+			Context: ".",
+			DockerfileInline: `
+FROM alpine:3.19 AS base
+FROM nginx:1.25
+`,
+		},
+	}
+	outdated, err := tested.outdatedBaseImages(context.Background(), service)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, outdated, []string{"nginx:1.25"})
+}
+
+func TestCheckBaseImagesRegistries(t *testing.T) {
+	allowed := []string{"docker.io", "registry.example.com"}
+	service := types.ServiceConfig{
+		Name: "test",
+		Build: &types.BuildConfig{
+			Context: ".",
+			DockerfileInline: `
+FROM golang:1.21 AS build
+FROM registry.example.com/base/runtime:1.0 AS runtime
+FROM build AS test
+FROM scratch
+`,
+		},
+	}
+	assert.NilError(t, checkBaseImagesRegistries(service, nil, allowed))
+
+	service.Build.DockerfileInline = `
+FROM golang:1.21 AS build
+FROM ghcr.io/acme/runtime:1.0
+`
+	err := checkBaseImagesRegistries(service, nil, allowed)
+	assert.ErrorContains(t, err, `service "test" base image "ghcr.io/acme/runtime:1.0" uses registry "ghcr.io" which is not allowed`)
+}
+
+func TestCheckBaseImagesRegistriesBuildArgs(t *testing.T) {
+	allowed := []string{"registry.example.com"}
+	service := types.ServiceConfig{
+		Name: "test",
+		Build: &types.BuildConfig{
+			Context: ".",
+			DockerfileInline: `
+ARG REGISTRY=registry.example.com
+ARG BASE="${REGISTRY}/base/runtime:1.0"
+FROM $REGISTRY/base/golang:1.21 AS build
+FROM ${BASE}
+`,
+		},
+	}
+	assert.NilError(t, checkBaseImagesRegistries(service, nil, allowed))
+
+	// build args override ARG default value
+	args := types.NewMappingWithEquals([]string{"REGISTRY=ghcr.io"})
+	err := checkBaseImagesRegistries(service, args, allowed)
+	assert.ErrorContains(t, err, `service "test" base image "ghcr.io/base/golang:1.21" uses registry "ghcr.io" which is not allowed`)
+
+	// variable without a value could point to any registry
+	service.Build.DockerfileInline = `
+ARG BASE
+FROM ${BASE}
+`
+	err = checkBaseImagesRegistries(service, nil, allowed)
+	assert.ErrorContains(t, err, `service "test" base image "${BASE}" can't be resolved`)
+	assert.Assert(t, compose.IsForbiddenError(err))
+
+	// ARG declared within a stage can't be used by FROM
+	service.Build.DockerfileInline = `
+FROM registry.example.com/base/golang:1.21
+ARG BASE=registry.example.com/base/runtime:1.0
+FROM ${BASE}
+`
+	err = checkBaseImagesRegistries(service, nil, allowed)
+	assert.ErrorContains(t, err, `can't be resolved`)
+}
+
+func TestCheckBaseImagesRegistriesSources(t *testing.T) {
+	allowed := []string{"docker.io", "registry.example.com"}
+	service := types.ServiceConfig{
+		Name: "test",
+		Build: &types.BuildConfig{
+			Context: ".",
+			DockerfileInline: `
+FROM golang:1.21 AS build
+RUN --mount=type=cache,target=/root/.cache \
+    --mount=type=bind,from=registry.example.com/tools:1.0,target=/tools go build
+FROM \
+  registry.example.com/base/runtime:1.0
+COPY --from=build /app /app
+COPY --from=0 /src /src
+`,
+		},
+	}
+	assert.NilError(t, checkBaseImagesRegistries(service, nil, allowed))
+
+	tests := []struct {
+		dockerfile string
+		image      string
+	}{
+		{dockerfile: "FROM \\\n  ghcr.io/acme/runtime:1.0\n", image: "ghcr.io/acme/runtime:1.0"},
+		{dockerfile: "FROM alpine\nCOPY --from=ghcr.io/acme/tools:1.0 /bin/tool /bin/tool\n", image: "ghcr.io/acme/tools:1.0"},
+		{dockerfile: "FROM alpine\nRUN --mount=type=bind,from=ghcr.io/acme/tools:1.0,target=/tools /tools/run\n", image: "ghcr.io/acme/tools:1.0"},
+	}
+	for _, tt := range tests {
+		service.Build.DockerfileInline = tt.dockerfile
+		err := checkBaseImagesRegistries(service, nil, allowed)
+		assert.ErrorContains(t, err, `base image "`+tt.image+`" uses registry "ghcr.io" which is not allowed`)
+	}
+
+	// named context replaces base image, and is checked on its own
+	service.Build.DockerfileInline = "FROM runtime\n"
+	service.Build.AdditionalContexts = types.Mapping{"runtime": "docker-image://registry.example.com/base/runtime:1.0"}
+	assert.NilError(t, checkBaseImagesRegistries(service, nil, allowed))
+	service.Build.AdditionalContexts = types.Mapping{"runtime": "docker-image://ghcr.io/acme/runtime:1.0"}
+	err := checkBaseImagesRegistries(service, nil, allowed)
+	assert.ErrorContains(t, err, `base image "ghcr.io/acme/runtime:1.0" uses registry "ghcr.io" which is not allowed`)
+}
+
+func TestCheckBaseImagesRegistriesRemoteContext(t *testing.T) {
+	service := types.ServiceConfig{
+		Name:  "test",
+		Build: &types.BuildConfig{Context: "https://github.com/docker/compose.git#main"},
+	}
+	err := checkBaseImagesRegistries(service, nil, []string{"docker.io"})
+	assert.ErrorContains(t, err, `service "test" uses a remote build context, base images can't be verified`)
+	assert.Assert(t, compose.IsForbiddenError(err))
+}
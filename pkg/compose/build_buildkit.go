@@ -19,6 +19,7 @@ package compose
 import (
 	"context"
 	"crypto/sha1"
+	"errors"
 	"fmt"
 
 	"github.com/docker/buildx/build"
@@ -34,36 +35,109 @@ import (
 	"github.com/moby/buildkit/client"
 )
 
-func (s *composeService) doBuildBuildkit(ctx context.Context, service string, opts build.Options, p *buildx.Printer, nodes []builder.Node) (string, error) {
+// buildkitBuild runs builds on BuildKit nodes, declared as a variable so tests can stub it
+var buildkitBuild = build.Build
+
+func (s *composeService) doBuildBuildkit(ctx context.Context, service string, opts build.Options, p buildx.Writer, nodes []builder.Node) (string, error) {
+	results, err := s.doBuildBuildkitMulti(ctx, map[string]build.Options{service: opts}, p, nodes)
+	if err != nil {
+		return "", err
+	}
+	return results[service].digest, nil
+}
+
+// buildkitResult holds the outcome of a service build
+type buildkitResult struct {
+	digest           string
+	exporterResponse map[string]string
+}
+
+// doBuildBuildkitMulti builds multiple services within a single BuildKit session, and returns build results
+// indexed by service name
+func (s *composeService) doBuildBuildkitMulti(ctx context.Context, opts map[string]build.Options, p buildx.Writer, nodes []builder.Node) (map[string]buildkitResult, error) {
 	var (
-		response map[string]*client.SolveResponse
+		response = map[string]*client.SolveResponse{}
 		err      error
 	)
 	if s.dryRun {
-		response = s.dryRunBuildResponse(ctx, service, opts)
+		for service, o := range opts {
+			for k, v := range s.dryRunBuildResponse(ctx, service, o) {
+				response[k] = v
+			}
+		}
 	} else {
-		response, err = build.Build(ctx, nodes,
-			map[string]build.Options{service: opts},
+		// buildx prefixes progress with service name when building multiple targets
+		w := p
+		if len(opts) == 1 {
+			for service := range opts {
+				w = buildx.WithPrefix(p, service, true)
+			}
+		}
+		response, err = buildkitBuild(ctx, nodes,
+			opts,
 			dockerutil.NewClient(s.dockerCli),
 			confutil.ConfigDir(s.dockerCli),
-			buildx.WithPrefix(p, service, true))
+			w)
 		if err != nil {
-			return "", WrapCategorisedComposeError(err, BuildFailure)
+			return nil, WrapCategorisedComposeError(err, BuildFailure)
 		}
 	}
 
-	for _, img := range response {
-		if img == nil || len(img.ExporterResponse) == 0 {
+	results := map[string]buildkitResult{}
+	for service, o := range opts {
+		if img, ok := response[service]; ok && img != nil {
+			if digest, ok := img.ExporterResponse["containerimage.digest"]; ok {
+				results[service] = buildkitResult{
+					digest:           digest,
+					exporterResponse: img.ExporterResponse,
+				}
+				continue
+			}
+		}
+		if len(o.Exports) == 0 {
+			// nothing exported, build only populated the build cache
 			continue
 		}
-		digest, ok := img.ExporterResponse["containerimage.digest"]
-		if !ok {
+		if !exportsImage(o.Exports) {
+			// local, tar and oci exporters write build result to files, without an image digest
+			var exporterResponse map[string]string
+			if img, ok := response[service]; ok && img != nil {
+				exporterResponse = img.ExporterResponse
+			}
+			results[service] = buildkitResult{exporterResponse: exporterResponse}
 			continue
 		}
-		return digest, nil
+		return nil, fmt.Errorf("buildkit response is missing expected result for %s", service)
 	}
+	return results, nil
+}
+
+// exportsImage returns true if any of the exports produces an image, which BuildKit reports by its digest
+func exportsImage(exports []client.ExportEntry) bool {
+	for _, e := range exports {
+		switch e.Type {
+		case client.ExporterLocal, client.ExporterTar, client.ExporterOCI:
+		default:
+			return true
+		}
+	}
+	return false
+}
 
-	return "", fmt.Errorf("buildkit response is missing expected result for %s", service)
+// checkBuilderNodes reports an error when none of the builder nodes could be loaded, so that a
+// misconfigured docker-container or kubernetes builder fails with the driver error rather than later on
+func checkBuilderNodes(name string, nodes []builder.Node) error {
+	var errs []error
+	for _, node := range nodes {
+		if node.Err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("node %q: %w", node.Name, node.Err))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("failed to load builder %q: %w", name, errors.Join(errs...))
 }
 
 func (s composeService) dryRunBuildResponse(ctx context.Context, name string, options build.Options) map[string]*client.SolveResponse {
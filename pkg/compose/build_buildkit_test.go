@@ -0,0 +1,83 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/buildx/build"
+	"github.com/docker/buildx/builder"
+	"github.com/docker/buildx/store"
+	"github.com/docker/buildx/util/dockerutil"
+	buildx "github.com/docker/buildx/util/progress"
+	"github.com/moby/buildkit/client"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestCheckBuilderNodes(t *testing.T) {
+	failed := builder.Node{Node: store.Node{Name: "node0"}, Err: errors.New("failed to find pod")}
+	available := builder.Node{Node: store.Node{Name: "node1"}}
+
+	assert.NilError(t, checkBuilderNodes("k8s", nil))
+	assert.NilError(t, checkBuilderNodes("k8s", []builder.Node{failed, available}))
+
+	err := checkBuilderNodes("k8s", []builder.Node{failed})
+	assert.Error(t, err, `failed to load builder "k8s": node "node0": failed to find pod`)
+}
+
+func TestDoBuildBuildkitMultiLocalOutput(t *testing.T) {
+	defer func(b func(context.Context, []builder.Node, map[string]build.Options, *dockerutil.Client, string, buildx.Writer) (map[string]*client.SolveResponse, error)) {
+		buildkitBuild = b
+	}(buildkitBuild)
+	// local exporter doesn't report any image digest
+	buildkitBuild = func(_ context.Context, _ []builder.Node, opts map[string]build.Options, _ *dockerutil.Client, _ string, _ buildx.Writer) (map[string]*client.SolveResponse, error) {
+		response := map[string]*client.SolveResponse{}
+		for name := range opts {
+			response[name] = &client.SolveResponse{ExporterResponse: map[string]string{}}
+		}
+		return response, nil
+	}
+
+	tested := prepareBuildService(t)
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {Name: "app", Build: &types.BuildConfig{Context: "."}},
+			"db":  {Name: "db", Build: &types.BuildConfig{Context: "."}},
+		},
+	}
+	options := api.BuildOptions{Outputs: map[string][]string{
+		"app": {"type=local,dest=" + t.TempDir()},
+	}}
+	app, err := tested.toBuildOptions(project, project.Services["app"], options)
+	assert.NilError(t, err)
+
+	results, err := tested.doBuildBuildkitMulti(context.Background(), map[string]build.Options{"app": app}, nil, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, results["app"].digest, "")
+
+	// an image export still requires a digest
+	db, err := tested.toBuildOptions(project, project.Services["db"], options)
+	assert.NilError(t, err)
+	_, err = tested.doBuildBuildkitMulti(context.Background(), map[string]build.Options{"db": db}, nil, nil)
+	assert.Error(t, err, "buildkit response is missing expected result for db")
+}
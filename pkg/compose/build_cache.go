@@ -0,0 +1,88 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+	"github.com/docker/buildx/controller/pb"
+	"github.com/docker/buildx/util/buildflags"
+)
+
+// unreachableCacheSources returns the registry references declared by service's cache_from
+// which can't be resolved, so user can understand why build didn't benefit from cache
+func (s *composeService) unreachableCacheSources(ctx context.Context, service types.ServiceConfig) ([]string, error) {
+	if service.Build == nil || len(service.Build.CacheFrom) == 0 {
+		return nil, nil
+	}
+	entries, err := buildflags.ParseCacheEntry(service.Build.CacheFrom)
+	if err != nil {
+		return nil, err
+	}
+	var unreachable []string
+	for _, entry := range entries {
+		if entry.Type != "registry" {
+			continue
+		}
+		ref, ok := entry.Attrs["ref"]
+		if !ok {
+			continue
+		}
+		named, err := reference.ParseNormalizedNamed(ref)
+		if err != nil {
+			return nil, err
+		}
+		auth, err := encodedAuth(named, s.configFile())
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.apiClient().DistributionInspect(ctx, named.String(), auth); err != nil {
+			unreachable = append(unreachable, ref)
+		}
+	}
+	return unreachable, nil
+}
+
+func (s *composeService) warnUnreachableCacheSources(ctx context.Context, service types.ServiceConfig) error {
+	unreachable, err := s.unreachableCacheSources(ctx, service)
+	if err != nil {
+		return err
+	}
+	for _, ref := range unreachable {
+		fmt.Fprintf(s.stderr(), "WARNING: service %q cache_from %q can't be reached, build will run without this cache\n", service.Name, ref)
+	}
+	return nil
+}
+
+// resolveLocalCachePaths makes `src` and `dest` paths of local cache entries relative to project directory,
+// so that cache location doesn't depend on the directory compose is ran from
+func resolveLocalCachePaths(entries []*pb.CacheOptionsEntry, workingDir string) {
+	for _, entry := range entries {
+		if entry.Type != "local" {
+			continue
+		}
+		for _, attr := range []string{"src", "dest"} {
+			if path, ok := entry.Attrs[attr]; ok && !filepath.IsAbs(path) {
+				entry.Attrs[attr] = filepath.Join(workingDir, path)
+			}
+		}
+	}
+}
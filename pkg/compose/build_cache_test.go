@@ -0,0 +1,60 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/docker/api/types/registry"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+func TestUnreachableCacheSources(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	api.EXPECT().DistributionInspect(gomock.Any(), "registry.example.com/cache/reachable", gomock.Any()).
+		Return(registry.DistributionInspect{}, nil)
+	api.EXPECT().DistributionInspect(gomock.Any(), "registry.example.com/cache/unreachable", gomock.Any()).
+		Return(registry.DistributionInspect{}, errors.New("connection refused"))
+
+	service := types.ServiceConfig{
+		Name: "test",
+		Build: &types.BuildConfig{
+			Context: ".",
+			CacheFrom: []string{
+				"registry.example.com/cache/reachable",
+				"type=registry,ref=registry.example.com/cache/unreachable",
+				"type=local,src=/tmp/cache",
+			},
+		},
+	}
+	unreachable, err := tested.unreachableCacheSources(context.Background(), service)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, unreachable, []string{"registry.example.com/cache/unreachable"})
+}
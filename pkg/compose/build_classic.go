@@ -78,12 +78,24 @@ func (s *composeService) doBuildClassic(ctx context.Context, project *types.Proj
 	if len(service.Build.Secrets) > 0 {
 		return "", fmt.Errorf("the classic builder doesn't support secrets, set DOCKER_BUILDKIT=1 to use BuildKit")
 	}
+	if options.InlineCache {
+		return "", fmt.Errorf("the classic builder doesn't support inline cache, set DOCKER_BUILDKIT=1 to use BuildKit")
+	}
+	if len(options.NoCacheFilter) > 0 {
+		return "", fmt.Errorf("the classic builder doesn't support no-cache filter, set DOCKER_BUILDKIT=1 to use BuildKit")
+	}
 
 	if service.Build.Labels == nil {
 		service.Build.Labels = make(map[string]string)
 	}
 	service.Build.Labels[api.ImageBuilderLabel] = "classic"
 
+	if service.Build.DockerfileInline != "" {
+		// send inline Dockerfile the same way `docker build -f -` does for stdin
+		dockerfileName = "-"
+		dockerfileCtx = io.NopCloser(strings.NewReader(service.Build.DockerfileInline))
+	}
+
 	switch {
 	case isLocalDir(specifiedContext):
 		contextDir, relDockerfile, err = build.GetContextFromLocalDir(specifiedContext, dockerfileName)
@@ -114,7 +126,15 @@ func (s *composeService) doBuildClassic(ctx context.Context, project *types.Proj
 
 	// read from a directory into tar archive
 	if buildCtx == nil {
-		excludes, err := build.ReadDockerignore(contextDir)
+		var excludes []string
+		if ignoreFile, ok := options.IgnoreFiles[service.Name]; ok {
+			if err := checkIgnoreFile(ignoreFile); err != nil {
+				return "", err
+			}
+			excludes, err = readIgnoreFile(ignoreFile)
+		} else {
+			excludes, err = build.ReadDockerignore(contextDir)
+		}
 		if err != nil {
 			return "", err
 		}
@@ -225,11 +245,13 @@ func imageBuildOptions(dockerCli command.Cli, project *types.Project, service ty
 		Tags:        config.Tags,
 		NoCache:     config.NoCache,
 		Remove:      true,
+		ForceRemove: options.ForceRemove,
 		PullParent:  config.Pull,
 		BuildArgs:   resolveAndMergeBuildArgs(dockerCli, project, service, options),
 		Labels:      config.Labels,
 		NetworkMode: config.Network,
 		ExtraHosts:  config.ExtraHosts.AsList(":"),
+		ShmSize:     int64(config.ShmSize),
 		Target:      config.Target,
 		Isolation:   container.Isolation(config.Isolation),
 	}
@@ -0,0 +1,93 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/config/configfile"
+	moby "github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/archive"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestDoBuildClassicDockerfileInline(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "app.txt"), []byte("hello"), 0o644))
+	dockerfile := "FROM alpine\nCOPY app.txt /app.txt\n"
+	project := &types.Project{Name: "test"}
+	service := types.ServiceConfig{
+		Name:  "app",
+		Build: &types.BuildConfig{Context: dir, DockerfileInline: dockerfile},
+	}
+
+	apiClient.EXPECT().ImageBuild(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, body io.Reader, options moby.ImageBuildOptions) (moby.ImageBuildResponse, error) {
+			// inline Dockerfile is sent within build context under a generated name, like `docker build -f -`
+			assert.Check(t, options.Dockerfile != "-")
+			files := readBuildContext(t, body)
+			assert.Equal(t, files[options.Dockerfile], dockerfile)
+			assert.Equal(t, files["app.txt"], "hello")
+			return moby.ImageBuildResponse{
+				Body: io.NopCloser(strings.NewReader(`{"aux":{"ID":"sha256:app"}}` + "\n")),
+			}, nil
+		})
+
+	id, err := tested.doBuildClassic(context.Background(), project, service, api.BuildOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, id, "sha256:app")
+}
+
+// readBuildContext returns content of files sent as build context
+func readBuildContext(t *testing.T, body io.Reader) map[string]string {
+	t.Helper()
+	stream, err := archive.DecompressStream(body)
+	assert.NilError(t, err)
+	defer stream.Close() //nolint:errcheck
+	files := map[string]string{}
+	reader := tar.NewReader(stream)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return files
+		}
+		assert.NilError(t, err)
+		content, err := io.ReadAll(reader)
+		assert.NilError(t, err)
+		files[header.Name] = string(content)
+	}
+}
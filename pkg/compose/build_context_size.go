@@ -0,0 +1,103 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/moby/patternmatcher"
+)
+
+// contextIgnorePatterns returns the patterns BuildKit applies to filter service build context: ignore file
+// set by caller, `<Dockerfile>.dockerignore` next to the Dockerfile, or `.dockerignore` at context root
+func contextIgnorePatterns(build *types.BuildConfig, ignoreFile string) ([]string, error) {
+	if ignoreFile != "" {
+		return readIgnoreFile(ignoreFile)
+	}
+	candidates := []string{filepath.Join(build.Context, ".dockerignore")}
+	if dockerfile := dockerFilePath(build.Context, build.Dockerfile); dockerfile != "" {
+		candidates = append([]string{dockerfile + ".dockerignore"}, candidates...)
+	}
+	for _, candidate := range candidates {
+		patterns, err := readIgnoreFile(candidate)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return patterns, err
+	}
+	return nil, nil
+}
+
+// contextSize computes the size of files from a local build context which are sent to the builder,
+// excluding those matching ignore patterns
+func contextSize(contextDir string, patterns []string) (int64, error) {
+	pm, err := patternmatcher.New(patterns)
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	err = filepath.WalkDir(contextDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(contextDir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		excluded, err := pm.MatchesOrParentMatches(rel)
+		if err != nil {
+			return err
+		}
+		if excluded {
+			// exclusion patterns (`!file`) might re-include some content
+			if d.IsDir() && !pm.Exclusions() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// exceedsContextSize checks whether local build context for service is larger than limit, and returns its size
+func exceedsContextSize(service types.ServiceConfig, ignoreFile string, limit int64) (int64, bool, error) {
+	if _, err := os.Stat(service.Build.Context); err != nil || isRemoteContext(service.Build.Context) {
+		// remote context is fetched by builder, and an invalid one will make build fail anyway
+		return 0, false, nil
+	}
+	patterns, err := contextIgnorePatterns(service.Build, ignoreFile)
+	if err != nil {
+		return 0, false, err
+	}
+	size, err := contextSize(service.Build.Context, patterns)
+	if err != nil {
+		return 0, false, err
+	}
+	return size, size > limit, nil
+}
@@ -0,0 +1,92 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestContextSize(t *testing.T) {
+	dir := t.TempDir()
+	for name, size := range map[string]int{
+		"Dockerfile":              10,
+		"main.go":                 100,
+		"debug.log":               1000,
+		"keep.log":                20,
+		"node_modules/dep/lib.js": 10000,
+	} {
+		path := filepath.Join(dir, name)
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NilError(t, os.WriteFile(path, []byte(strings.Repeat("x", size)), 0o644))
+	}
+
+	size, err := contextSize(dir, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, size, int64(11130))
+
+	size, err = contextSize(dir, []string{"node_modules", "*.log", "!keep.log"})
+	assert.NilError(t, err)
+	assert.Equal(t, size, int64(130))
+}
+
+func TestContextIgnorePatterns(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, ".dockerignore"), []byte("node_modules\n"), 0o644))
+	build := &types.BuildConfig{Context: dir}
+
+	patterns, err := contextIgnorePatterns(build, "")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, patterns, []string{"node_modules"})
+
+	build.Dockerfile = "prod.Dockerfile"
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "prod.Dockerfile.dockerignore"), []byte("*.log\n"), 0o644))
+	patterns, err = contextIgnorePatterns(build, "")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, patterns, []string{"*.log"})
+
+	custom := filepath.Join(t.TempDir(), "custom.ignore")
+	assert.NilError(t, os.WriteFile(custom, []byte("tmp\n"), 0o644))
+	patterns, err = contextIgnorePatterns(build, custom)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, patterns, []string{"tmp"})
+}
+
+func TestExceedsContextSize(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine\n"), 0o644))
+	service := types.ServiceConfig{Name: "app", Build: &types.BuildConfig{Context: dir}}
+
+	size, exceeds, err := exceedsContextSize(service, "", 10)
+	assert.NilError(t, err)
+	assert.Check(t, exceeds)
+	assert.Equal(t, size, int64(12))
+
+	_, exceeds, err = exceedsContextSize(service, "", 1024)
+	assert.NilError(t, err)
+	assert.Check(t, !exceeds)
+
+	service.Build.Context = "https://github.com/docker/compose.git"
+	_, exceeds, err = exceedsContextSize(service, "", 1)
+	assert.NilError(t, err)
+	assert.Check(t, !exceeds)
+}
@@ -0,0 +1,164 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	buildx "github.com/docker/buildx/util/progress"
+	"github.com/moby/buildkit/client"
+	"github.com/opencontainers/go-digest"
+	"github.com/sirupsen/logrus"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// buildEventsEmitter streams build events as newline-delimited JSON to an external consumer
+type buildEventsEmitter struct {
+	events chan api.BuildEvent
+	drop   bool
+	closer io.Closer
+	done   chan struct{}
+}
+
+// newBuildEventsEmitter creates an emitter according to build options, or returns nil if events are not requested
+func newBuildEventsEmitter(options api.BuildOptions) (*buildEventsEmitter, error) {
+	out := options.EventsConn
+	var closer io.Closer
+	if out == nil {
+		if options.EventsSocket == "" {
+			return nil, nil
+		}
+		conn, err := net.Dial("unix", options.EventsSocket)
+		if err != nil {
+			return nil, err
+		}
+		out, closer = conn, conn
+	}
+
+	e := &buildEventsEmitter{
+		events: make(chan api.BuildEvent, 100),
+		drop:   options.EventsDrop,
+		closer: closer,
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(e.done)
+		encoder := json.NewEncoder(out)
+		for event := range e.events {
+			if err := encoder.Encode(event); err != nil {
+				logrus.Debugf("failed to send build event: %v", err)
+			}
+		}
+	}()
+	return e, nil
+}
+
+func (e *buildEventsEmitter) emit(service string, eventType string, message string) {
+	e.send(api.BuildEvent{
+		Service: service,
+		Type:    eventType,
+		Message: message,
+		Time:    time.Now(),
+	})
+}
+
+func (e *buildEventsEmitter) send(event api.BuildEvent) {
+	if !e.drop {
+		e.events <- event
+		return
+	}
+	select {
+	case e.events <- event:
+	default:
+		logrus.Debugf("build event consumer is too slow, dropping event %s for %s", event.Type, event.Service)
+	}
+}
+
+// Close flushes pending events and releases the connection opened by emitter
+func (e *buildEventsEmitter) Close() error {
+	close(e.events)
+	<-e.done
+	if e.closer != nil {
+		return e.closer.Close()
+	}
+	return nil
+}
+
+// writer wraps a BuildKit progress writer to emit an event for each completed build step. When service is
+// empty, steps are attributed to the service BuildKit prefixes their name with, as it does for multiple targets
+func (e *buildEventsEmitter) writer(w buildx.Writer, service string) buildx.Writer {
+	return &buildEventsWriter{
+		Writer:    w,
+		emitter:   e,
+		service:   service,
+		completed: map[digest.Digest]bool{},
+	}
+}
+
+type buildEventsWriter struct {
+	buildx.Writer
+	emitter   *buildEventsEmitter
+	service   string
+	mux       sync.Mutex
+	completed map[digest.Digest]bool
+}
+
+func (w *buildEventsWriter) Write(status *client.SolveStatus) {
+	w.mux.Lock()
+	for _, v := range status.Vertexes {
+		if v.Completed == nil || w.completed[v.Digest] {
+			continue
+		}
+		w.completed[v.Digest] = true
+		service, step := w.service, v.Name
+		if service == "" {
+			service, step = splitStepPrefix(v.Name)
+		}
+		w.emitter.send(api.BuildEvent{
+			Service: service,
+			Type:    api.BuildEventStep,
+			Message: step,
+			Cached:  v.Cached,
+			Time:    *v.Completed,
+		})
+	}
+	w.mux.Unlock()
+	w.Writer.Write(status)
+}
+
+// splitStepPrefix splits a step name prefixed by BuildKit as `[service] name` or `[service 1/2] name`
+// into service name and original step name
+func splitStepPrefix(name string) (string, string) {
+	if !strings.HasPrefix(name, "[") {
+		return "", name
+	}
+	end := strings.IndexAny(name, " ]")
+	if end < 0 {
+		return "", name
+	}
+	service := name[1:end]
+	if name[end] == ']' {
+		return service, strings.TrimPrefix(name[end+1:], " ")
+	}
+	return service, "[" + name[end+1:]
+}
@@ -0,0 +1,111 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	"github.com/opencontainers/go-digest"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+type nopProgressWriter struct{}
+
+func (w *nopProgressWriter) Write(*client.SolveStatus) {}
+
+func (w *nopProgressWriter) WriteBuildRef(string, string) {}
+
+func (w *nopProgressWriter) ValidateLogSource(digest.Digest, interface{}) bool {
+	return true
+}
+
+func (w *nopProgressWriter) ClearLogSource(interface{}) {}
+
+func TestBuildEventsSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "events.sock")
+	listener, err := net.Listen("unix", socket)
+	assert.NilError(t, err)
+	defer listener.Close() //nolint:errcheck
+
+	received := make(chan []api.BuildEvent)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			close(received)
+			return
+		}
+		defer conn.Close() //nolint:errcheck
+		var events []api.BuildEvent
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			var event api.BuildEvent
+			if err := json.Unmarshal(scanner.Bytes(), &event); err == nil {
+				events = append(events, event)
+			}
+		}
+		received <- events
+	}()
+
+	emitter, err := newBuildEventsEmitter(api.BuildOptions{EventsSocket: socket})
+	assert.NilError(t, err)
+
+	now := time.Now()
+	emitter.emit("app", api.BuildEventStart, "")
+	w := emitter.writer(&nopProgressWriter{}, "app")
+	w.Write(&client.SolveStatus{Vertexes: []*client.Vertex{
+		{Digest: "sha256:1", Name: "[1/2] FROM alpine", Started: &now, Completed: &now, Cached: true},
+		{Digest: "sha256:2", Name: "[2/2] RUN make", Started: &now},
+	}})
+	w.Write(&client.SolveStatus{Vertexes: []*client.Vertex{
+		{Digest: "sha256:1", Name: "[1/2] FROM alpine", Started: &now, Completed: &now, Cached: true},
+		{Digest: "sha256:2", Name: "[2/2] RUN make", Started: &now, Completed: &now},
+	}})
+	emitter.emit("app", api.BuildEventFinish, "sha256:abc")
+	assert.NilError(t, emitter.Close())
+
+	events := <-received
+	assert.Equal(t, len(events), 4)
+	assert.Equal(t, events[0].Type, api.BuildEventStart)
+	assert.Equal(t, events[1].Type, api.BuildEventStep)
+	assert.Equal(t, events[1].Message, "[1/2] FROM alpine")
+	assert.Check(t, events[1].Cached)
+	assert.Equal(t, events[2].Message, "[2/2] RUN make")
+	assert.Equal(t, events[3].Type, api.BuildEventFinish)
+	assert.Equal(t, events[3].Message, "sha256:abc")
+}
+
+func TestSplitStepPrefix(t *testing.T) {
+	service, step := splitStepPrefix("[api 1/2] FROM alpine")
+	assert.Equal(t, service, "api")
+	assert.Equal(t, step, "[1/2] FROM alpine")
+
+	service, step = splitStepPrefix("[worker] load build definition from Dockerfile")
+	assert.Equal(t, service, "worker")
+	assert.Equal(t, step, "load build definition from Dockerfile")
+
+	service, step = splitStepPrefix("resolve image config")
+	assert.Equal(t, service, "")
+	assert.Equal(t, step, "resolve image config")
+}
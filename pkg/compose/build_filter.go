@@ -0,0 +1,88 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"sync"
+
+	buildx "github.com/docker/buildx/util/progress"
+	"github.com/moby/buildkit/client"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// cachedStepsFilter drops build steps resolved from cache from solve status before they reach the
+// progress printer, so that output only reports steps actually executed
+type cachedStepsFilter struct {
+	buildx.Writer
+	mux    sync.Mutex
+	cached map[digest.Digest]bool
+}
+
+func newCachedStepsFilter(w buildx.Writer) buildx.Writer {
+	return &cachedStepsFilter{
+		Writer: w,
+		cached: map[digest.Digest]bool{},
+	}
+}
+
+func (f *cachedStepsFilter) Write(status *client.SolveStatus) {
+	f.mux.Lock()
+	filtered := &client.SolveStatus{}
+	for _, v := range status.Vertexes {
+		if v.Cached {
+			f.cached[v.Digest] = true
+		}
+		if f.cached[v.Digest] {
+			continue
+		}
+		filtered.Vertexes = append(filtered.Vertexes, v)
+	}
+	for _, s := range status.Statuses {
+		if !f.cached[s.Vertex] {
+			filtered.Statuses = append(filtered.Statuses, s)
+		}
+	}
+	for _, l := range status.Logs {
+		if !f.cached[l.Vertex] {
+			filtered.Logs = append(filtered.Logs, l)
+		}
+	}
+	for _, w := range status.Warnings {
+		if !f.cached[w.Vertex] {
+			filtered.Warnings = append(filtered.Warnings, w)
+		}
+	}
+	f.mux.Unlock()
+
+	if len(filtered.Vertexes)+len(filtered.Statuses)+len(filtered.Logs)+len(filtered.Warnings) == 0 {
+		return
+	}
+	f.Writer.Write(filtered)
+}
+
+// serviceProgressWriter wraps w to apply display filters and emit build events for service, according to options
+func serviceProgressWriter(w buildx.Writer, service string, options api.BuildOptions, emitter *buildEventsEmitter) buildx.Writer {
+	if options.HideCachedSteps {
+		w = newCachedStepsFilter(w)
+	}
+	if emitter != nil {
+		w = emitter.writer(w, service)
+	}
+	return w
+}
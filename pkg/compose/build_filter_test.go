@@ -0,0 +1,104 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+type recordingProgressWriter struct {
+	nopProgressWriter
+	statuses []*client.SolveStatus
+}
+
+func (w *recordingProgressWriter) Write(status *client.SolveStatus) {
+	w.statuses = append(w.statuses, status)
+}
+
+func TestCachedStepsFilter(t *testing.T) {
+	recorder := &recordingProgressWriter{}
+	filter := newCachedStepsFilter(recorder)
+
+	filter.Write(&client.SolveStatus{
+		Vertexes: []*client.Vertex{
+			{Digest: "sha256:cached", Name: "[1/2] COPY go.mod .", Cached: true},
+			{Digest: "sha256:executed", Name: "[2/2] RUN go build"},
+		},
+	})
+	filter.Write(&client.SolveStatus{
+		Logs: []*client.VertexLog{
+			{Vertex: "sha256:cached", Data: []byte("cached output")},
+			{Vertex: "sha256:executed", Data: []byte("build output")},
+		},
+	})
+	filter.Write(&client.SolveStatus{
+		Statuses: []*client.VertexStatus{
+			{Vertex: "sha256:cached", ID: "cached layer"},
+		},
+	})
+
+	assert.Equal(t, len(recorder.statuses), 2)
+	assert.Equal(t, len(recorder.statuses[0].Vertexes), 1)
+	assert.Equal(t, recorder.statuses[0].Vertexes[0].Name, "[2/2] RUN go build")
+	assert.Equal(t, len(recorder.statuses[1].Logs), 1)
+	assert.Equal(t, string(recorder.statuses[1].Logs[0].Data), "build output")
+}
+
+func TestServiceProgressWriterHideCachedStepsWithEvents(t *testing.T) {
+	var out bytes.Buffer
+	options := api.BuildOptions{EventsConn: &out, HideCachedSteps: true}
+	emitter, err := newBuildEventsEmitter(options)
+	assert.NilError(t, err)
+
+	recorder := &recordingProgressWriter{}
+	w := serviceProgressWriter(recorder, "app", options, emitter)
+
+	now := time.Now()
+	w.Write(&client.SolveStatus{
+		Vertexes: []*client.Vertex{
+			{Digest: "sha256:cached", Name: "[1/2] COPY go.mod .", Cached: true, Completed: &now},
+			{Digest: "sha256:executed", Name: "[2/2] RUN go build", Completed: &now},
+		},
+	})
+	assert.NilError(t, emitter.Close())
+
+	// cached step is hidden from progress output
+	assert.Equal(t, len(recorder.statuses), 1)
+	assert.Equal(t, len(recorder.statuses[0].Vertexes), 1)
+	assert.Equal(t, recorder.statuses[0].Vertexes[0].Name, "[2/2] RUN go build")
+
+	// but still reported as a build event
+	var events []api.BuildEvent
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var event api.BuildEvent
+		assert.NilError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	assert.Equal(t, len(events), 2)
+	assert.Equal(t, events[0].Service, "app")
+	assert.Assert(t, events[0].Cached)
+}
@@ -0,0 +1,95 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/buildx/build"
+	"github.com/moby/patternmatcher/ignorefile"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// checkIgnoreFile makes sure ignore file set for a service build can be used
+func checkIgnoreFile(path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("ignore file %q can't be accessed: %w", path, err)
+	}
+	if fi.IsDir() {
+		return fmt.Errorf("ignore file %q is a directory", path)
+	}
+	return nil
+}
+
+func readIgnoreFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+	patterns, err := ignorefile.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	return patterns, nil
+}
+
+// withIgnoreFile makes BuildKit use ignoreFile to filter build context. Dockerfile frontend looks for a
+// `<Dockerfile>.dockerignore` next to the Dockerfile, so we relocate Dockerfile into a temporary directory
+// along with the ignore file, created under tempDir or the system default if empty. Returned func removes
+// this temporary directory.
+func withIgnoreFile(inputs build.Inputs, service types.ServiceConfig, ignoreFile string, tempDir string) (build.Inputs, func(), error) {
+	if err := checkIgnoreFile(ignoreFile); err != nil {
+		return inputs, nil, err
+	}
+	dockerfile, err := readDockerfile(service.Build)
+	if err != nil {
+		return inputs, nil, err
+	}
+	if dockerfile == "" {
+		return inputs, nil, api.NewServiceError(service.Name, api.ErrInvalidConfig, "ignore file can't be set for service %q using a remote build context", service.Name)
+	}
+	ignore, err := os.ReadFile(ignoreFile)
+	if err != nil {
+		return inputs, nil, err
+	}
+
+	dir, err := os.MkdirTemp(tempDir, "compose-build-")
+	if err != nil {
+		return inputs, nil, err
+	}
+	cleanup := func() {
+		_ = os.RemoveAll(dir)
+	}
+	dockerfilePath := filepath.Join(dir, "Dockerfile")
+	if err := os.WriteFile(dockerfilePath, []byte(dockerfile), 0o600); err != nil {
+		cleanup()
+		return inputs, nil, err
+	}
+	if err := os.WriteFile(dockerfilePath+".dockerignore", ignore, 0o600); err != nil {
+		cleanup()
+		return inputs, nil, err
+	}
+	inputs.DockerfilePath = dockerfilePath
+	inputs.DockerfileInline = ""
+	return inputs, cleanup, nil
+}
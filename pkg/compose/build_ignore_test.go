@@ -0,0 +1,67 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/buildx/build"
+	"github.com/moby/patternmatcher"
+	"gotest.tools/v3/assert"
+)
+
+func TestWithIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine\nCOPY . /src\n"), 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, ".dockerignore"), []byte("*.md\n"), 0o644))
+	ignoreFile := filepath.Join(dir, "api.dockerignore")
+	assert.NilError(t, os.WriteFile(ignoreFile, []byte("secrets.env\n"), 0o644))
+
+	service := types.ServiceConfig{
+		Name:  "api",
+		Build: &types.BuildConfig{Context: dir},
+	}
+	inputs, cleanup, err := withIgnoreFile(build.Inputs{ContextPath: dir}, service, ignoreFile, "")
+	assert.NilError(t, err)
+	defer cleanup()
+
+	assert.Equal(t, inputs.ContextPath, dir)
+	dockerfile, err := os.ReadFile(inputs.DockerfilePath)
+	assert.NilError(t, err)
+	assert.Equal(t, string(dockerfile), "FROM alpine\nCOPY . /src\n")
+
+	patterns, err := readIgnoreFile(inputs.DockerfilePath + ".dockerignore")
+	assert.NilError(t, err)
+	pm, err := patternmatcher.New(patterns)
+	assert.NilError(t, err)
+	excluded, err := pm.MatchesOrParentMatches("secrets.env")
+	assert.NilError(t, err)
+	assert.Check(t, excluded)
+	excluded, err = pm.MatchesOrParentMatches("README.md")
+	assert.NilError(t, err)
+	assert.Check(t, !excluded)
+
+	cleanup()
+	_, err = os.Stat(inputs.DockerfilePath)
+	assert.Check(t, os.IsNotExist(err))
+
+	_, _, err = withIgnoreFile(build.Inputs{ContextPath: dir}, service, filepath.Join(dir, "missing.dockerignore"), "")
+	assert.ErrorContains(t, err, "can't be accessed")
+}
@@ -0,0 +1,133 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/opencontainers/go-digest"
+)
+
+// buildManifest records services which have been successfully built, so that an interrupted build
+// can be resumed without rebuilding images already produced
+type buildManifest struct {
+	Services map[string]buildManifestEntry `json:"services"`
+
+	path string
+	mux  sync.Mutex
+}
+
+type buildManifestEntry struct {
+	Hash   string `json:"hash"`
+	Digest string `json:"digest"`
+}
+
+func loadBuildManifest(path string) (*buildManifest, error) {
+	m := &buildManifest{
+		Services: map[string]buildManifestEntry{},
+		path:     path,
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, m); err != nil {
+		return nil, fmt.Errorf("invalid build manifest %s: %w", path, err)
+	}
+	if m.Services == nil {
+		m.Services = map[string]buildManifestEntry{}
+	}
+	return m, nil
+}
+
+// reconcile drops entries for services which are not part of the project anymore, or whose
+// build configuration changed since they were recorded
+func (m *buildManifest) reconcile(project *types.Project) error {
+	for name, entry := range m.Services {
+		service, ok := project.Services[name]
+		if !ok || service.Build == nil {
+			delete(m.Services, name)
+			continue
+		}
+		hash, err := buildConfigHash(service)
+		if err != nil {
+			return err
+		}
+		if hash != entry.Hash || entry.Digest == "" {
+			delete(m.Services, name)
+		}
+	}
+	return nil
+}
+
+// completed returns the digest of the image recorded as built for service
+func (m *buildManifest) completed(service types.ServiceConfig) (string, bool) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	entry, ok := m.Services[service.Name]
+	if !ok {
+		return "", false
+	}
+	hash, err := buildConfigHash(service)
+	if err != nil || hash != entry.Hash {
+		return "", false
+	}
+	return entry.Digest, true
+}
+
+// record marks service as built and saves the manifest, so progress is preserved if build gets interrupted
+func (m *buildManifest) record(service types.ServiceConfig, digest string) error {
+	hash, err := buildConfigHash(service)
+	if err != nil {
+		return err
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.Services[service.Name] = buildManifestEntry{
+		Hash:   hash,
+		Digest: digest,
+	}
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.path, content, 0o644)
+}
+
+// buildConfigHash computes the hash for service build configuration
+func buildConfigHash(service types.ServiceConfig) (string, error) {
+	bytes, err := json.Marshal(struct {
+		Image string             `json:"image,omitempty"`
+		Build *types.BuildConfig `json:"build,omitempty"`
+	}{
+		Image: service.Image,
+		Build: service.Build,
+	})
+	if err != nil {
+		return "", err
+	}
+	return digest.SHA256.FromBytes(bytes).Encoded(), nil
+}
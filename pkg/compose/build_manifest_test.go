@@ -0,0 +1,61 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestBuildManifestResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "build-manifest.json")
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"front":  {Name: "front", Build: &types.BuildConfig{Context: "./front"}},
+			"back":   {Name: "back", Build: &types.BuildConfig{Context: "./back"}},
+			"worker": {Name: "worker", Build: &types.BuildConfig{Context: "./worker"}},
+		},
+	}
+
+	// simulate a partial run which got interrupted after building front and back
+	manifest, err := loadBuildManifest(path)
+	assert.NilError(t, err)
+	assert.NilError(t, manifest.record(project.Services["front"], "sha256:front"))
+	assert.NilError(t, manifest.record(project.Services["back"], "sha256:back"))
+
+	// back build configuration changed since
+	back := project.Services["back"]
+	back.Build = &types.BuildConfig{Context: "./back", Target: "prod"}
+	project.Services["back"] = back
+
+	resumed, err := loadBuildManifest(path)
+	assert.NilError(t, err)
+	assert.NilError(t, resumed.reconcile(project))
+
+	digest, ok := resumed.completed(project.Services["front"])
+	assert.Check(t, ok)
+	assert.Equal(t, digest, "sha256:front")
+	_, ok = resumed.completed(project.Services["back"])
+	assert.Check(t, !ok)
+	_, ok = resumed.completed(project.Services["worker"])
+	assert.Check(t, !ok)
+	assert.DeepEqual(t, len(resumed.Services), 1)
+}
@@ -0,0 +1,116 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// serviceContextPrefix is used by additional_contexts to reference another service's image
+const serviceContextPrefix = "service:"
+
+// contextService returns the service an additional context refers to with `service:name`. As loader resolves
+// additional contexts as local paths, reference might have been turned into an absolute path
+func contextService(context string) (string, bool) {
+	if dep, ok := strings.CutPrefix(context, serviceContextPrefix); ok {
+		return dep, true
+	}
+	if filepath.IsAbs(context) {
+		return strings.CutPrefix(filepath.Base(context), serviceContextPrefix)
+	}
+	return "", false
+}
+
+// imageDependencies returns, for each service to build, the other services to build which image it consumes,
+// either as a Dockerfile base image, a `COPY --from` or `RUN --mount=from=` source, or as an additional context, and
+// so must be built first
+func imageDependencies(project *types.Project, services map[string]serviceToBuild) map[string][]string {
+	images := map[string]string{}
+	for name, s := range services {
+		if ref := normalizedImageRef(api.GetImageNameOrDefault(s.service, project.Name)); ref != "" {
+			images[ref] = name
+		}
+	}
+
+	dependencies := map[string][]string{}
+	for name, s := range services {
+		deps := map[string]bool{}
+		for _, context := range s.service.Build.AdditionalContexts {
+			if dep, ok := contextService(context); ok {
+				if _, ok := services[dep]; ok {
+					deps[dep] = true
+				}
+			}
+		}
+		// Dockerfile can't be read for remote contexts, and invalid ones will make build fail anyway
+		if dockerfile, err := readDockerfile(s.service.Build); err == nil {
+			consumed, _, _ := dockerfileImages(dockerfile, nil, true)
+			for _, image := range consumed {
+				if dep, ok := images[normalizedImageRef(image)]; ok {
+					deps[dep] = true
+				}
+			}
+		}
+		delete(deps, name)
+		for dep := range deps {
+			dependencies[name] = append(dependencies[name], dep)
+		}
+		sort.Strings(dependencies[name])
+	}
+	return dependencies
+}
+
+func normalizedImageRef(image string) string {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return ""
+	}
+	return reference.TagNameOnly(named).String()
+}
+
+// withImageDependencies returns a copy of project to compute build order, where services also depend on the
+// services which image they consume
+func withImageDependencies(project *types.Project, dependencies map[string][]string) *types.Project {
+	if len(dependencies) == 0 {
+		return project
+	}
+	p := *project
+	p.Services = types.Services{}
+	for name, service := range project.Services {
+		if deps, ok := dependencies[name]; ok {
+			dependsOn := types.DependsOnConfig{}
+			for k, v := range service.DependsOn {
+				dependsOn[k] = v
+			}
+			for _, dep := range deps {
+				if _, ok := dependsOn[dep]; !ok {
+					dependsOn[dep] = types.ServiceDependency{Condition: types.ServiceConditionStarted, Required: true}
+				}
+			}
+			service.DependsOn = dependsOn
+		}
+		p.Services[name] = service
+	}
+	return &p
+}
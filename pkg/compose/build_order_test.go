@@ -0,0 +1,86 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestImageDependencies(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"base.Dockerfile": "FROM alpine\n",
+		"app.Dockerfile":  "FROM golang AS builder\nFROM test-base:latest\nCOPY --from=builder /app /app\n",
+		"tool.Dockerfile": "FROM alpine\nCOPY --from=base / /\n",
+	} {
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"base": {Name: "base", Build: &types.BuildConfig{Context: dir, Dockerfile: "base.Dockerfile"}},
+			"app":  {Name: "app", Build: &types.BuildConfig{Context: dir, Dockerfile: "app.Dockerfile"}},
+			"tool": {Name: "tool", Build: &types.BuildConfig{
+				Context:            dir,
+				Dockerfile:         "tool.Dockerfile",
+				AdditionalContexts: types.Mapping{"base": "service:base"},
+			}},
+			"db": {Name: "db", Image: "postgres"},
+		},
+	}
+	services := map[string]serviceToBuild{}
+	for _, name := range []string{"base", "app", "tool"} {
+		services[name] = serviceToBuild{name: name, service: project.Services[name]}
+	}
+
+	deps := imageDependencies(project, services)
+	assert.DeepEqual(t, deps, map[string][]string{
+		"app":  {"base"},
+		"tool": {"base"},
+	})
+
+	ordered := withImageDependencies(project, deps)
+	assert.DeepEqual(t, ordered.Services["app"].GetDependencies(), []string{"base"})
+	assert.DeepEqual(t, ordered.Services["tool"].GetDependencies(), []string{"base"})
+	// original project is left unchanged
+	assert.Equal(t, len(project.Services["app"].DependsOn), 0)
+
+	contexts := toBuildContexts(project, project.Services["tool"].Build.AdditionalContexts)
+	assert.Equal(t, contexts["base"].Path, "docker-image://test-base")
+}
+
+func TestToBuildContextsResolvedServiceReference(t *testing.T) {
+	dir := t.TempDir()
+	project := &types.Project{
+		Name:       "test",
+		WorkingDir: dir,
+		Services: types.Services{
+			"base": {Name: "base", Build: &types.BuildConfig{Context: dir}},
+		},
+	}
+	contexts := toBuildContexts(project, types.Mapping{
+		"base":   filepath.Join(dir, "service:base"),
+		"assets": filepath.Join(dir, "assets"),
+	})
+	assert.Equal(t, contexts["base"].Path, "docker-image://test-base")
+	assert.Equal(t, contexts["assets"].Path, filepath.Join(dir, "assets"))
+}
@@ -0,0 +1,68 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"io"
+	"os"
+
+	"github.com/containerd/console"
+	"github.com/moby/buildkit/util/progress/progressui"
+
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+// progressOutput returns the console.File BuildKit progress display writes to
+func progressOutput(out io.Writer) console.File {
+	if out == nil {
+		return os.Stdout
+	}
+	if f, ok := out.(console.File); ok {
+		return f
+	}
+	return writerFile{out}
+}
+
+// writerFile adapts a plain io.Writer to console.File. As it has no file descriptor, BuildKit can't
+// detect a terminal and falls back to plain output in auto mode
+type writerFile struct {
+	io.Writer
+}
+
+func (writerFile) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (writerFile) Close() error {
+	return nil
+}
+
+func (writerFile) Fd() uintptr {
+	return ^uintptr(0)
+}
+
+func (writerFile) Name() string {
+	return ""
+}
+
+// progressDisplayMode converts compose progress mode into BuildKit's
+func progressDisplayMode(mode string) progressui.DisplayMode {
+	if mode == progress.ModeJSON {
+		return progressui.RawJSONMode
+	}
+	return progressui.DisplayMode(mode)
+}
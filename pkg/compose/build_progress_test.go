@@ -0,0 +1,45 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/containerd/console"
+	"github.com/moby/buildkit/util/progress/progressui"
+	"gotest.tools/v3/assert"
+)
+
+func TestProgressOutput(t *testing.T) {
+	assert.Equal(t, progressOutput(nil), console.File(os.Stdout))
+
+	var buf bytes.Buffer
+	out := progressOutput(&buf)
+	_, err := out.Write([]byte("#1 DONE"))
+	assert.NilError(t, err)
+	assert.Equal(t, buf.String(), "#1 DONE")
+	// not a terminal, so BuildKit display falls back to plain output
+	_, err = console.ConsoleFromFile(out)
+	assert.Check(t, err != nil)
+}
+
+func TestProgressDisplayMode(t *testing.T) {
+	assert.Equal(t, progressDisplayMode("json"), progressui.RawJSONMode)
+	assert.Equal(t, progressDisplayMode("plain"), progressui.PlainMode)
+}
@@ -0,0 +1,140 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/platforms"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/moby/buildkit/util/attestation"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+const (
+	// imageNameResponseKey is the exporter response entry listing the image names build was exported as
+	imageNameResponseKey = "image.name"
+	// inTotoMediaType is the media type of attestation layers
+	inTotoMediaType = "application/vnd.in-toto+json"
+	// predicateTypeAnnotation identifies the kind of attestation stored in an attestation layer
+	predicateTypeAnnotation = "in-toto.io/predicate-type"
+	// slsaPredicatePrefix is the predicate type prefix of SLSA provenance attestations
+	slsaPredicatePrefix = "https://slsa.dev/provenance/"
+)
+
+// descriptorFetcher fetches content addressed by a descriptor from the repository of an image reference
+type descriptorFetcher interface {
+	GetDescriptor(ctx context.Context, ref string, desc ocispec.Descriptor) ([]byte, error)
+}
+
+// readProvenance retrieves the provenance attestations attached to a pushed image, indexed by platform. BuildKit
+// attaches attestations to the image index as attestation manifests, so they are looked up from the index
+// descriptor set in exporter response. Images which haven't been exported as an index have no attestation.
+func readProvenance(ctx context.Context, fetcher descriptorFetcher, exporterResponse map[string]string) (map[string][]byte, error) {
+	name, _, _ := strings.Cut(exporterResponse[imageNameResponseKey], ",")
+	encoded, ok := exporterResponse[exptypes.ExporterImageDescriptorKey]
+	if name == "" || !ok {
+		return nil, nil
+	}
+	dt, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var desc ocispec.Descriptor
+	if err := json.Unmarshal(dt, &desc); err != nil {
+		return nil, err
+	}
+	if desc.MediaType != ocispec.MediaTypeImageIndex && desc.MediaType != images.MediaTypeDockerSchema2ManifestList {
+		return nil, nil
+	}
+
+	var index ocispec.Index
+	if err := fetchJSON(ctx, fetcher, name, desc, &index); err != nil {
+		return nil, err
+	}
+	platformsByDigest := map[string]string{}
+	for _, m := range index.Manifests {
+		if m.Platform != nil {
+			platformsByDigest[m.Digest.String()] = platforms.Format(*m.Platform)
+		}
+	}
+
+	provenance := map[string][]byte{}
+	for _, m := range index.Manifests {
+		if m.Annotations[attestation.DockerAnnotationReferenceType] != attestation.DockerAnnotationReferenceTypeDefault {
+			continue
+		}
+		var manifest ocispec.Manifest
+		if err := fetchJSON(ctx, fetcher, name, m, &manifest); err != nil {
+			return nil, err
+		}
+		for _, layer := range manifest.Layers {
+			if layer.MediaType != inTotoMediaType || !strings.HasPrefix(layer.Annotations[predicateTypeAnnotation], slsaPredicatePrefix) {
+				continue
+			}
+			statement, err := fetcher.GetDescriptor(ctx, name, layer)
+			if err != nil {
+				return nil, err
+			}
+			provenance[platformsByDigest[m.Annotations[attestation.DockerAnnotationReferenceDigest]]] = statement
+		}
+	}
+	return provenance, nil
+}
+
+func fetchJSON(ctx context.Context, fetcher descriptorFetcher, ref string, desc ocispec.Descriptor, v any) error {
+	dt, err := fetcher.GetDescriptor(ctx, ref, desc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(dt, v)
+}
+
+// writeProvenance writes provenance attestation of service build as `<service>.provenance.json` into dir. For a
+// multi-platform image, one `<service>.<os>-<arch>.provenance.json` file is written per platform.
+func writeProvenance(ctx context.Context, fetcher descriptorFetcher, dir string, service string, exporterResponse map[string]string) error {
+	provenance, err := readProvenance(ctx, fetcher, exporterResponse)
+	if err != nil {
+		return api.NewServiceError(service, api.ErrUnknown, "failed to read provenance attestation for service %q: %w", service, err)
+	}
+	if len(provenance) == 0 {
+		logrus.Warnf("no provenance attestation available for service %q", service)
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for platform, statement := range provenance {
+		name := service
+		if len(provenance) > 1 && platform != "" {
+			name += "." + strings.ReplaceAll(platform, "/", "-")
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".provenance.json"), statement, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
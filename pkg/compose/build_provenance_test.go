@@ -0,0 +1,127 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+// fakeRegistry serves content by digest, as a registry does for a repository
+type fakeRegistry map[digest.Digest][]byte
+
+func (r fakeRegistry) add(t *testing.T, mediaType string, v any) ocispec.Descriptor {
+	var dt []byte
+	switch v := v.(type) {
+	case []byte:
+		dt = v
+	default:
+		var err error
+		dt, err = json.Marshal(v)
+		assert.NilError(t, err)
+	}
+	dgst := digest.FromBytes(dt)
+	r[dgst] = dt
+	return ocispec.Descriptor{MediaType: mediaType, Digest: dgst, Size: int64(len(dt))}
+}
+
+func (r fakeRegistry) GetDescriptor(_ context.Context, ref string, desc ocispec.Descriptor) ([]byte, error) {
+	if ref != "registry.example.com/app:1.0" {
+		return nil, fmt.Errorf("unexpected reference %s", ref)
+	}
+	dt, ok := r[desc.Digest]
+	if !ok {
+		return nil, fmt.Errorf("%s: not found", desc.Digest)
+	}
+	return dt, nil
+}
+
+// pushImage stores an image index with attestation manifests for platforms the way BuildKit image exporter
+// does, and returns exporter response
+func (r fakeRegistry) pushImage(t *testing.T, platforms ...ocispec.Platform) map[string]string {
+	index := ocispec.Index{MediaType: ocispec.MediaTypeImageIndex}
+	for _, p := range platforms {
+		p := p
+		config := r.add(t, ocispec.MediaTypeImageConfig, ocispec.Image{Platform: p})
+		image := r.add(t, ocispec.MediaTypeImageManifest, ocispec.Manifest{MediaType: ocispec.MediaTypeImageManifest, Config: config})
+		image.Platform = &p
+		statement := r.add(t, "application/vnd.in-toto+json", []byte(fmt.Sprintf(`{"predicateType":"https://slsa.dev/provenance/v0.2","subject":%q}`, p.Architecture)))
+		statement.Annotations = map[string]string{"in-toto.io/predicate-type": "https://slsa.dev/provenance/v0.2"}
+		attestation := r.add(t, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+			MediaType: ocispec.MediaTypeImageManifest,
+			Config:    r.add(t, ocispec.MediaTypeImageConfig, ocispec.Image{}),
+			Layers:    []ocispec.Descriptor{statement},
+		})
+		attestation.Platform = &ocispec.Platform{OS: "unknown", Architecture: "unknown"}
+		attestation.Annotations = map[string]string{
+			"vnd.docker.reference.type":   "attestation-manifest",
+			"vnd.docker.reference.digest": image.Digest.String(),
+		}
+		index.Manifests = append(index.Manifests, image, attestation)
+	}
+	desc := r.add(t, ocispec.MediaTypeImageIndex, index)
+	dtdesc, err := json.Marshal(desc)
+	assert.NilError(t, err)
+	return map[string]string{
+		"containerimage.digest":     desc.Digest.String(),
+		"containerimage.descriptor": base64.StdEncoding.EncodeToString(dtdesc),
+		"image.name":                "registry.example.com/app:1.0",
+	}
+}
+
+func TestWriteProvenance(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "provenance")
+	registry := fakeRegistry{}
+
+	response := registry.pushImage(t, ocispec.Platform{OS: "linux", Architecture: "amd64"})
+	assert.NilError(t, writeProvenance(context.Background(), registry, dir, "front", response))
+	content, err := os.ReadFile(filepath.Join(dir, "front.provenance.json"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), `{"predicateType":"https://slsa.dev/provenance/v0.2","subject":"amd64"}`)
+
+	response = registry.pushImage(t, ocispec.Platform{OS: "linux", Architecture: "amd64"}, ocispec.Platform{OS: "linux", Architecture: "arm64"})
+	assert.NilError(t, writeProvenance(context.Background(), registry, dir, "back", response))
+	content, err = os.ReadFile(filepath.Join(dir, "back.linux-arm64.provenance.json"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), `{"predicateType":"https://slsa.dev/provenance/v0.2","subject":"arm64"}`)
+	_, err = os.Stat(filepath.Join(dir, "back.linux-amd64.provenance.json"))
+	assert.NilError(t, err)
+}
+
+func TestReadProvenanceWithoutIndex(t *testing.T) {
+	registry := fakeRegistry{}
+	// single manifest, as exported to image store, has no attestation
+	desc := registry.add(t, ocispec.MediaTypeImageManifest, ocispec.Manifest{MediaType: ocispec.MediaTypeImageManifest})
+	dtdesc, err := json.Marshal(desc)
+	assert.NilError(t, err)
+	provenance, err := readProvenance(context.Background(), registry, map[string]string{
+		"containerimage.digest":     desc.Digest.String(),
+		"containerimage.descriptor": base64.StdEncoding.EncodeToString(dtdesc),
+		"image.name":                "registry.example.com/app:1.0",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(provenance), 0)
+}
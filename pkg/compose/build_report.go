@@ -0,0 +1,116 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/compose-spec/compose-go/v2/types"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// newBuildReport creates a report for the built images, indexed by service name
+func newBuildReport(ctx context.Context, project *types.Project, digests map[string]string) api.BuildReport {
+	sources := serviceSourceFiles(ctx, project)
+	report := api.BuildReport{
+		Project: project.Name,
+	}
+	for name, digest := range digests {
+		report.Services = append(report.Services, api.ServiceBuildReport{
+			Service:    name,
+			Image:      api.GetImageNameOrDefault(project.Services[name], project.Name),
+			Digest:     digest,
+			SourceFile: sources[name],
+		})
+	}
+	sort.Slice(report.Services, func(i, j int) bool {
+		return report.Services[i].Service < report.Services[j].Service
+	})
+	return report
+}
+
+func writeBuildReport(path string, report api.BuildReport) error {
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+// serviceSourceFiles maps services to the compose file declaring them. Files are loaded by compose-go so that
+// services brought by `include` are attributed to the included file, while a service declared by several files,
+// like an override, is attributed to the first one. Files which can't be loaded are ignored, as they failed to be
+// loaded already.
+func serviceSourceFiles(ctx context.Context, project *types.Project) map[string]string {
+	sources := map[string]string{}
+	loaded := map[string]bool{}
+	var collect func(file string)
+	collect = func(file string) {
+		if loaded[file] {
+			return
+		}
+		loaded[file] = true
+
+		var included []string
+		model, err := loader.LoadModelWithContext(ctx, types.ConfigDetails{
+			WorkingDir:  filepath.Dir(file),
+			ConfigFiles: []types.ConfigFile{{Filename: file}},
+			Environment: project.Environment.Clone(),
+		}, func(options *loader.Options) {
+			options.SetProjectName(project.Name, true)
+			options.SkipValidation = true
+			options.SkipConsistencyCheck = true
+			options.SkipResolveEnvironment = true
+			options.Listeners = []loader.Listener{func(event string, metadata map[string]any) {
+				if event != "include" {
+					return
+				}
+				paths, _ := metadata["path"].(types.StringList)
+				workingDir, _ := metadata["workingdir"].(string)
+				for _, path := range paths {
+					if !filepath.IsAbs(path) {
+						path = filepath.Join(workingDir, path)
+					}
+					included = append(included, path)
+				}
+			}}
+		})
+		if err != nil {
+			return
+		}
+		// model has services from included files merged, let those claim their own services first
+		for _, path := range included {
+			collect(path)
+		}
+		services, _ := model["services"].(map[string]any)
+		for name := range services {
+			if _, ok := sources[name]; !ok {
+				sources[name] = file
+			}
+		}
+	}
+	for _, file := range project.ComposeFiles {
+		collect(file)
+	}
+	return sources
+}
@@ -0,0 +1,85 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestBuildReportSourceAttribution(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "compose.yaml")
+	override := filepath.Join(dir, "compose.override.yaml")
+	included := filepath.Join(dir, "db", "compose.yaml")
+	assert.NilError(t, os.MkdirAll(filepath.Dir(included), 0o755))
+	assert.NilError(t, os.WriteFile(included, []byte(`
+services:
+  db:
+    build: .
+`), 0o644))
+	assert.NilError(t, os.WriteFile(base, []byte(`
+include:
+  - db/compose.yaml
+services:
+  front:
+    build: ./front
+  back:
+    build: ./back
+`), 0o644))
+	assert.NilError(t, os.WriteFile(override, []byte(`
+services:
+  back:
+    build:
+      target: dev
+  worker:
+    build: ./worker
+`), 0o644))
+
+	project := &types.Project{
+		Name:         "test",
+		ComposeFiles: []string{base, override},
+		Services: types.Services{
+			"front":  {Name: "front"},
+			"back":   {Name: "back"},
+			"worker": {Name: "worker"},
+			"db":     {Name: "db"},
+		},
+	}
+	report := newBuildReport(context.Background(), project, map[string]string{
+		"front":  "sha256:1",
+		"back":   "sha256:2",
+		"worker": "sha256:3",
+		"db":     "sha256:4",
+	})
+	assert.DeepEqual(t, report, api.BuildReport{
+		Project: "test",
+		Services: []api.ServiceBuildReport{
+			{Service: "back", Image: "test-back", Digest: "sha256:2", SourceFile: base},
+			{Service: "db", Image: "test-db", Digest: "sha256:4", SourceFile: included},
+			{Service: "front", Image: "test-front", Digest: "sha256:1", SourceFile: base},
+			{Service: "worker", Image: "test-worker", Digest: "sha256:3", SourceFile: override},
+		},
+	})
+}
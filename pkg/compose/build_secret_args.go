@@ -0,0 +1,73 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/moby/buildkit/session/secrets/secretsprovider"
+)
+
+// secretBuildArgs reads build args values from project secrets, using the same provider as build secrets.
+// args maps build arg names to the secret to read value from
+func secretBuildArgs(ctx context.Context, project *types.Project, args map[string]string) (types.MappingWithEquals, error) {
+	var sources []secretsprovider.Source
+	seen := map[string]bool{}
+	for arg, secret := range args {
+		config, ok := project.Secrets[secret]
+		if !ok {
+			return nil, fmt.Errorf("build arg %q refers to undefined secret %q", arg, secret)
+		}
+		if seen[secret] {
+			continue
+		}
+		seen[secret] = true
+		switch {
+		case config.File != "":
+			sources = append(sources, secretsprovider.Source{
+				ID:       secret,
+				FilePath: config.File,
+			})
+		case config.Environment != "":
+			sources = append(sources, secretsprovider.Source{
+				ID:  secret,
+				Env: config.Environment,
+			})
+		default:
+			return nil, fmt.Errorf("build args only support environment or file-based secrets: %q", secret)
+		}
+	}
+	store, err := secretsprovider.NewStore(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := types.MappingWithEquals{}
+	for arg, secret := range args {
+		value, err := store.GetSecret(ctx, secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secret %q for build arg %q: %w", secret, arg, err)
+		}
+		// secret files commonly end with a newline which isn't part of the value
+		v := strings.TrimSuffix(string(value), "\n")
+		resolved[arg] = &v
+	}
+	return resolved, nil
+}
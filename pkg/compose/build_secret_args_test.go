@@ -0,0 +1,54 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestSecretBuildArgs(t *testing.T) {
+	token := filepath.Join(t.TempDir(), "token")
+	assert.NilError(t, os.WriteFile(token, []byte("s3cr3t\n"), 0o600))
+	t.Setenv("NPM_TOKEN", "npm-value")
+	project := &types.Project{
+		Secrets: types.Secrets{
+			"token":     {File: token},
+			"npm_token": {Environment: "NPM_TOKEN"},
+			"external":  {External: true},
+		},
+	}
+
+	args, err := secretBuildArgs(context.Background(), project, map[string]string{
+		"TOKEN":     "token",
+		"NPM_TOKEN": "npm_token",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, *args["TOKEN"], "s3cr3t")
+	assert.Equal(t, *args["NPM_TOKEN"], "npm-value")
+
+	_, err = secretBuildArgs(context.Background(), project, map[string]string{"TOKEN": "missing"})
+	assert.ErrorContains(t, err, `build arg "TOKEN" refers to undefined secret "missing"`)
+
+	_, err = secretBuildArgs(context.Background(), project, map[string]string{"TOKEN": "external"})
+	assert.ErrorContains(t, err, `build args only support environment or file-based secrets: "external"`)
+}
@@ -0,0 +1,124 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// sharedContextBuild builds a set of services sharing the same build context and Dockerfile
+// at once, so that context is only transferred and hashed once by BuildKit
+type sharedContextBuild struct {
+	services []string
+	once     sync.Once
+	running  sync.WaitGroup
+	done     chan struct{}
+	results  map[string]buildkitResult
+	err      error
+}
+
+// build starts the shared build within session context on first invocation, then waits for result for service.
+// Session isn't bound to any caller's context, so a cancelled caller doesn't fail the other services
+func (b *sharedContextBuild) build(ctx context.Context, session context.Context, service string, fn func(ctx context.Context, services []string) (map[string]buildkitResult, error)) (buildkitResult, error) {
+	b.once.Do(func() {
+		b.running.Add(1)
+		go func() {
+			defer b.running.Done()
+			defer close(b.done)
+			b.results, b.err = fn(session, b.services)
+		}()
+	})
+	select {
+	case <-ctx.Done():
+		return buildkitResult{}, ctx.Err()
+	case <-b.done:
+	}
+	if b.err != nil {
+		return buildkitResult{}, b.err
+	}
+	return b.results[service], nil
+}
+
+// waitSharedBuilds blocks until all shared builds which have been started are completed
+func waitSharedBuilds(shared map[string]*sharedContextBuild) {
+	for _, b := range shared {
+		b.running.Wait()
+	}
+}
+
+// sharedContexts groups services with identical build context, Dockerfile and ignore file. Only groups
+// with more than one service are returned, indexed by service name. Services consuming the image of another
+// service from the same group are left out, as a single build can't wait for the image it produces.
+func sharedContexts(services map[string]serviceToBuild, ignoreFiles map[string]string, dependencies map[string][]string) map[string]*sharedContextBuild {
+	groups := map[string][]string{}
+	for name, s := range services {
+		build := s.service.Build
+		if build == nil {
+			continue
+		}
+		key := build.Context + "\x00" + dockerFilePath(build.Context, build.Dockerfile) + "\x00" + build.DockerfileInline + "\x00" + ignoreFiles[name]
+		groups[key] = append(groups[key], name)
+	}
+
+	shared := map[string]*sharedContextBuild{}
+	for _, names := range groups {
+		names = independentServices(names, dependencies)
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		b := &sharedContextBuild{services: names, done: make(chan struct{})}
+		for _, name := range names {
+			shared[name] = b
+		}
+	}
+	return shared
+}
+
+// independentServices filters out names which depend, even transitively, on the image of another one
+// or which another one depends on
+func independentServices(names []string, dependencies map[string][]string) []string {
+	related := map[string]bool{}
+	for _, name := range names {
+		reachable := map[string]bool{}
+		var visit func(string)
+		visit = func(n string) {
+			for _, dep := range dependencies[n] {
+				if !reachable[dep] {
+					reachable[dep] = true
+					visit(dep)
+				}
+			}
+		}
+		visit(name)
+		for _, other := range names {
+			if other != name && reachable[other] {
+				related[name] = true
+				related[other] = true
+			}
+		}
+	}
+	var independent []string
+	for _, name := range names {
+		if !related[name] {
+			independent = append(independent, name)
+		}
+	}
+	return independent
+}
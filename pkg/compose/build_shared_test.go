@@ -0,0 +1,153 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestSharedContexts(t *testing.T) {
+	services := map[string]serviceToBuild{
+		"api": {name: "api", service: types.ServiceConfig{Name: "api", Build: &types.BuildConfig{
+			Context: "/src", Args: types.NewMappingWithEquals([]string{"TARGET=api"}),
+		}}},
+		"worker": {name: "worker", service: types.ServiceConfig{Name: "worker", Build: &types.BuildConfig{
+			Context: "/src", Args: types.NewMappingWithEquals([]string{"TARGET=worker"}),
+		}}},
+		"front": {name: "front", service: types.ServiceConfig{Name: "front", Build: &types.BuildConfig{
+			Context: "/src", Dockerfile: "front.Dockerfile",
+		}}},
+	}
+	shared := sharedContexts(services, nil, nil)
+	assert.Equal(t, len(shared), 2)
+	assert.Check(t, shared["api"] == shared["worker"])
+	assert.DeepEqual(t, shared["api"].services, []string{"api", "worker"})
+
+	calls := 0
+	build := func(ctx context.Context, services []string) (map[string]buildkitResult, error) {
+		calls++
+		results := map[string]buildkitResult{}
+		for _, s := range services {
+			results[s] = buildkitResult{digest: "sha256:" + s}
+		}
+		return results, nil
+	}
+	result, err := shared["api"].build(context.Background(), context.Background(), "api", build)
+	assert.NilError(t, err)
+	assert.Equal(t, result.digest, "sha256:api")
+	result, err = shared["worker"].build(context.Background(), context.Background(), "worker", build)
+	assert.NilError(t, err)
+	assert.Equal(t, result.digest, "sha256:worker")
+	assert.Equal(t, calls, 1)
+}
+
+func TestSharedContextsImageDependencies(t *testing.T) {
+	services := map[string]serviceToBuild{}
+	for _, name := range []string{"base", "app", "tools", "docs"} {
+		services[name] = serviceToBuild{name: name, service: types.ServiceConfig{Name: name, Build: &types.BuildConfig{Context: "/src"}}}
+	}
+	// app consumes base image through lib, which is built from another context
+	services["lib"] = serviceToBuild{name: "lib", service: types.ServiceConfig{Name: "lib", Build: &types.BuildConfig{Context: "/lib"}}}
+	dependencies := map[string][]string{
+		"app": {"lib"},
+		"lib": {"base"},
+	}
+	shared := sharedContexts(services, nil, dependencies)
+	assert.Equal(t, len(shared), 2)
+	assert.DeepEqual(t, shared["tools"].services, []string{"docs", "tools"})
+	_, ok := shared["app"]
+	assert.Check(t, !ok)
+	_, ok = shared["base"]
+	assert.Check(t, !ok)
+}
+
+func TestSharedContextsIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine\nCOPY . /src\n"), 0o644))
+	shared := filepath.Join(dir, "shared.dockerignore")
+	assert.NilError(t, os.WriteFile(shared, []byte("secrets.env\n"), 0o644))
+	front := filepath.Join(dir, "front.dockerignore")
+	assert.NilError(t, os.WriteFile(front, []byte("node_modules\n"), 0o644))
+
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"api":    {Name: "api", Build: &types.BuildConfig{Context: dir}},
+			"worker": {Name: "worker", Build: &types.BuildConfig{Context: dir}},
+			"front":  {Name: "front", Build: &types.BuildConfig{Context: dir}},
+		},
+	}
+	services := map[string]serviceToBuild{}
+	for name, service := range project.Services {
+		services[name] = serviceToBuild{name: name, service: service}
+	}
+	options := api.BuildOptions{IgnoreFiles: map[string]string{"api": shared, "worker": shared, "front": front}}
+
+	groups := sharedContexts(services, options.IgnoreFiles, nil)
+	assert.Equal(t, len(groups), 2)
+	assert.DeepEqual(t, groups["api"].services, []string{"api", "worker"})
+
+	// services built within the shared session still get their ignore file applied
+	tested := prepareBuildService(t)
+	for _, name := range groups["api"].services {
+		opts, cleanup, err := tested.serviceBuildOptions(project, project.Services[name], options)
+		assert.NilError(t, err)
+		ignore, err := os.ReadFile(opts.Inputs.DockerfilePath + ".dockerignore")
+		assert.NilError(t, err)
+		assert.Equal(t, string(ignore), "secrets.env\n")
+		cleanup()
+	}
+}
+
+func TestSharedContextBuildCancelledCaller(t *testing.T) {
+	b := &sharedContextBuild{services: []string{"api", "worker"}, done: make(chan struct{})}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	build := func(ctx context.Context, services []string) (map[string]buildkitResult, error) {
+		close(started)
+		<-release
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return map[string]buildkitResult{"worker": {digest: "sha256:worker"}}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() {
+		_, err := b.build(ctx, context.Background(), "api", build)
+		errs <- err
+	}()
+	<-started
+	cancel()
+	assert.ErrorIs(t, <-errs, context.Canceled)
+
+	// first caller being cancelled doesn't fail the session
+	close(release)
+	result, err := b.build(context.Background(), context.Background(), "worker", build)
+	assert.NilError(t, err)
+	assert.Equal(t, result.digest, "sha256:worker")
+	waitSharedBuilds(map[string]*sharedContextBuild{"api": b})
+}
@@ -0,0 +1,59 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+// tagWithDigest tags image with an immutable tag derived from its digest, as `image:sha-<short digest>`
+func (s *composeService) tagWithDigest(ctx context.Context, image string, imageDigest string) (string, error) {
+	tag, err := digestTag(image, imageDigest)
+	if err != nil {
+		return "", err
+	}
+	if err := s.apiClient().ImageTag(ctx, imageDigest, tag); err != nil {
+		return "", err
+	}
+	progress.ContextWriter(ctx).Event(progress.Event{
+		ID:     tag,
+		Status: progress.Done,
+		Text:   "Tagged",
+	})
+	return tag, nil
+}
+
+func digestTag(image string, imageDigest string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", err
+	}
+	d, err := digest.Parse(imageDigest)
+	if err != nil {
+		return "", err
+	}
+	tagged, err := reference.WithTag(reference.TrimNamed(named), "sha-"+d.Encoded()[:12])
+	if err != nil {
+		return "", err
+	}
+	return reference.FamiliarString(tagged), nil
+}
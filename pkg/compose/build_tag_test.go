@@ -0,0 +1,51 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+func TestTagWithDigest(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	const imageDigest = "sha256:7b3ccabffc97de872a30dfd234fd972a66d247c8cfc69b0550f276481852627c"
+	api.EXPECT().ImageTag(gomock.Any(), imageDigest, "registry.example.com/app:sha-7b3ccabffc97").Return(nil)
+
+	tag, err := tested.tagWithDigest(context.Background(), "registry.example.com/app:latest", imageDigest)
+	assert.NilError(t, err)
+	assert.Equal(t, tag, "registry.example.com/app:sha-7b3ccabffc97")
+}
+
+func TestDigestTag(t *testing.T) {
+	tag, err := digestTag("myproject-front", "sha256:7b3ccabffc97de872a30dfd234fd972a66d247c8cfc69b0550f276481852627c")
+	assert.NilError(t, err)
+	assert.Equal(t, tag, "myproject-front:sha-7b3ccabffc97")
+
+	_, err = digestTag("myproject-front", "not-a-digest")
+	assert.ErrorContains(t, err, "invalid checksum digest format")
+}
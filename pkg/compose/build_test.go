@@ -0,0 +1,645 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/buildx/build"
+	"github.com/docker/cli/cli/config/configfile"
+	dockertypes "github.com/docker/docker/api/types"
+	bclient "github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/auth/authprovider"
+	"github.com/moby/buildkit/session/secrets/secretsprovider"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestMergeExtraHosts(t *testing.T) {
+	global := types.HostsList{
+		"registry.local": {"10.0.0.1"},
+		"mirror.local":   {"10.0.0.2"},
+	}
+	service := types.HostsList{
+		"registry.local": {"192.168.1.1"},
+		"db.local":       {"192.168.1.2"},
+	}
+	merged := mergeExtraHosts(global, service)
+	assert.DeepEqual(t, merged, types.HostsList{
+		"registry.local": {"192.168.1.1"},
+		"mirror.local":   {"10.0.0.2"},
+		"db.local":       {"192.168.1.2"},
+	})
+	assert.DeepEqual(t, global["registry.local"], []string{"10.0.0.1"})
+}
+
+func TestToPushPlatformsOptions(t *testing.T) {
+	opts := build.Options{
+		Tags: []string{"registry.example.com/app"},
+		Platforms: []specs.Platform{
+			{OS: "linux", Architecture: "amd64"},
+			{OS: "linux", Architecture: "arm64"},
+		},
+		Exports: []bclient.ExportEntry{{
+			Type:  "image",
+			Attrs: map[string]string{"push": "false"},
+		}},
+	}
+
+	pushOpts, err := toPushPlatformsOptions(opts, []string{"linux/amd64"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, pushOpts.Platforms, []specs.Platform{{OS: "linux", Architecture: "amd64"}})
+	assert.DeepEqual(t, pushOpts.Exports, []bclient.ExportEntry{{
+		Type:  "image",
+		Attrs: map[string]string{"push": "true"},
+	}})
+	assert.Equal(t, len(opts.Platforms), 2)
+
+	_, err = toPushPlatformsOptions(opts, []string{"linux/s390x"})
+	assert.ErrorContains(t, err, `platform "linux/s390x" is not part of the built platforms`)
+}
+
+func TestEnvPassthroughSources(t *testing.T) {
+	sources, err := envPassthroughSources([]string{"NPM_TOKEN", "_CI"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, sources, []secretsprovider.Source{
+		{ID: "NPM_TOKEN", Env: "NPM_TOKEN"},
+		{ID: "_CI", Env: "_CI"},
+	})
+
+	_, err = envPassthroughSources([]string{"1INVALID"})
+	assert.ErrorContains(t, err, `invalid environment variable name "1INVALID"`)
+}
+
+func TestSelectPlatforms(t *testing.T) {
+	built := []specs.Platform{
+		{OS: "linux", Architecture: "amd64"},
+		{OS: "linux", Architecture: "arm64", Variant: "v8"},
+	}
+	selected, err := selectPlatforms(built, []string{"linux/arm64"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, selected, []specs.Platform{{OS: "linux", Architecture: "arm64", Variant: "v8"}})
+
+	_, err = selectPlatforms(built, []string{"linux/arm/v7"})
+	assert.ErrorContains(t, err, `platform "linux/arm/v7" is not part of the built platforms`)
+}
+
+func prepareBuildService(t *testing.T) composeService {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	apiClient.EXPECT().DaemonHost().Return("unix:///var/run/docker.sock").AnyTimes()
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	return composeService{
+		dockerCli: cli,
+	}
+}
+
+func TestToBuildOptionsLoadPlatform(t *testing.T) {
+	tested := prepareBuildService(t)
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {
+				Name: "app",
+				Build: &types.BuildConfig{
+					Context:   ".",
+					Platforms: []string{"linux/amd64", "linux/arm64"},
+				},
+			},
+		},
+	}
+	opts, err := tested.toBuildOptions(project, project.Services["app"], api.BuildOptions{LoadPlatform: "linux/arm64"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, opts.Platforms, []specs.Platform{{OS: "linux", Architecture: "arm64"}})
+	assert.Equal(t, opts.Exports[0].Type, "docker")
+	assert.Equal(t, opts.Exports[0].Attrs["load"], "true")
+
+	_, err = tested.toBuildOptions(project, project.Services["app"], api.BuildOptions{LoadPlatform: "linux/s390x"})
+	assert.ErrorContains(t, err, `platform "linux/s390x" is not part of the built platforms`)
+}
+
+func TestToBuildOptionsCacheOnly(t *testing.T) {
+	tested := prepareBuildService(t)
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {
+				Name:  "app",
+				Image: "registry.example.com/app",
+				Build: &types.BuildConfig{
+					Context: ".",
+					CacheTo: []string{"type=registry,ref=registry.example.com/app:cache"},
+				},
+			},
+		},
+	}
+	opts, err := tested.toBuildOptions(project, project.Services["app"], api.BuildOptions{CacheOnly: true})
+	assert.NilError(t, err)
+	assert.Equal(t, len(opts.Exports), 0)
+	assert.Equal(t, len(opts.CacheTo), 1)
+}
+
+func TestToBuildOptionsCacheControls(t *testing.T) {
+	tested := prepareBuildService(t)
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {
+				Name: "app",
+				Build: &types.BuildConfig{
+					Context: ".",
+					CacheTo: []string{"type=registry,ref=registry.example.com/app:cache"},
+				},
+			},
+		},
+	}
+	opts, err := tested.toBuildOptions(project, project.Services["app"], api.BuildOptions{
+		InlineCache:   true,
+		NoCacheFilter: []string{"deps"},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(opts.CacheTo), 2)
+	assert.Equal(t, opts.CacheTo[0].Type, "registry")
+	assert.Equal(t, opts.CacheTo[1].Type, "inline")
+	assert.DeepEqual(t, opts.NoCacheFilter, []string{"deps"})
+	assert.Check(t, !opts.NoCache)
+}
+
+func TestToBuildOptionsOutputs(t *testing.T) {
+	tested := prepareBuildService(t)
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {
+				Name:  "app",
+				Build: &types.BuildConfig{Context: "."},
+			},
+			"db": {
+				Name:  "db",
+				Build: &types.BuildConfig{Context: "."},
+			},
+		},
+	}
+	dir := t.TempDir()
+	options := api.BuildOptions{Outputs: map[string][]string{
+		"app": {"type=local,dest=" + dir},
+	}}
+	opts, err := tested.toBuildOptions(project, project.Services["app"], options)
+	assert.NilError(t, err)
+	assert.Equal(t, len(opts.Exports), 1)
+	assert.Equal(t, opts.Exports[0].Type, "local")
+	assert.Equal(t, opts.Exports[0].OutputDir, dir)
+
+	opts, err = tested.toBuildOptions(project, project.Services["db"], options)
+	assert.NilError(t, err)
+	assert.Equal(t, opts.Exports[0].Type, "docker")
+
+	options.Outputs["app"] = []string{"type=local"}
+	_, err = tested.toBuildOptions(project, project.Services["app"], options)
+	assert.ErrorContains(t, err, "dest is required for local exporter")
+}
+
+func TestToBuildOptionsAttestations(t *testing.T) {
+	tested := prepareBuildService(t)
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {
+				Name:  "app",
+				Build: &types.BuildConfig{Context: "."},
+			},
+		},
+	}
+	opts, err := tested.toBuildOptions(project, project.Services["app"], api.BuildOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(opts.Attests), 0)
+
+	opts, err = tested.toBuildOptions(project, project.Services["app"], api.BuildOptions{
+		Provenance: "mode=max",
+		SBOM:       "true",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, *opts.Attests["provenance"], "type=provenance,mode=max")
+	assert.Equal(t, *opts.Attests["sbom"], "type=sbom,disabled=false")
+
+	opts, err = tested.toBuildOptions(project, project.Services["app"], api.BuildOptions{Provenance: "false"})
+	assert.NilError(t, err)
+	provenance, ok := opts.Attests["provenance"]
+	assert.Check(t, ok)
+	assert.Check(t, provenance == nil)
+}
+
+func TestToBuildOptionsDockerfileInline(t *testing.T) {
+	tested := prepareBuildService(t)
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {
+				Name: "app",
+				Build: &types.BuildConfig{
+					Context:          ".",
+					DockerfileInline: "FROM alpine\nRUN echo hello\n",
+				},
+			},
+		},
+	}
+	opts, err := tested.toBuildOptions(project, project.Services["app"], api.BuildOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, opts.Inputs.DockerfileInline, "FROM alpine\nRUN echo hello\n")
+}
+
+func TestResolveAndMergeBuildArgsFromProjectEnvironment(t *testing.T) {
+	tested := prepareBuildService(t)
+	project := &types.Project{
+		Name: "test",
+		Environment: types.Mapping{
+			"GO_VERSION": "1.21",
+			"UNUSED":     "value",
+		},
+	}
+	service := types.ServiceConfig{
+		Name: "app",
+		Build: &types.BuildConfig{
+			Context: ".",
+			Args:    types.NewMappingWithEquals([]string{"GO_VERSION", "DEBUG=true", "MISSING"}),
+		},
+	}
+	args := resolveAndMergeBuildArgs(tested.dockerCli, project, service, api.BuildOptions{})
+	assert.DeepEqual(t, flatten(args), types.Mapping{
+		"GO_VERSION": "1.21",
+		"DEBUG":      "true",
+	})
+}
+
+func TestResolveAndMergeBuildArgsEnvironment(t *testing.T) {
+	tested := prepareBuildService(t)
+	t.Setenv("HOST_ONLY", "host")
+	service := types.ServiceConfig{
+		Name: "app",
+		Build: &types.BuildConfig{
+			Context: ".",
+			Args:    types.NewMappingWithEquals([]string{"GO_VERSION", "HOST_ONLY"}),
+		},
+	}
+
+	args := resolveAndMergeBuildArgs(tested.dockerCli, &types.Project{Name: "test"}, service, api.BuildOptions{
+		Environment: types.Mapping{"GO_VERSION": "1.22"},
+	})
+	assert.DeepEqual(t, flatten(args), types.Mapping{
+		"GO_VERSION": "1.22",
+		"HOST_ONLY":  "host",
+	})
+
+	project := &types.Project{
+		Name:        "test",
+		Environment: types.Mapping{"GO_VERSION": "1.21"},
+	}
+	args = resolveAndMergeBuildArgs(tested.dockerCli, project, service, api.BuildOptions{
+		Environment: types.Mapping{"GO_VERSION": "1.22"},
+	})
+	assert.DeepEqual(t, flatten(args), types.Mapping{
+		"GO_VERSION": "1.22",
+	})
+	assert.Equal(t, project.Environment["GO_VERSION"], "1.21")
+}
+
+func TestToBuildOptionsIndexAnnotations(t *testing.T) {
+	tested := prepareBuildService(t)
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {
+				Name:  "app",
+				Image: "registry.example.com/app",
+				Build: &types.BuildConfig{
+					Context:   ".",
+					Platforms: []string{"linux/amd64", "linux/arm64"},
+				},
+			},
+		},
+	}
+	options := api.BuildOptions{
+		Push: true,
+		IndexAnnotations: map[string]string{
+			"org.opencontainers.image.source": "https://github.com/example/app",
+		},
+	}
+	opts, err := tested.toBuildOptions(project, project.Services["app"], options)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, opts.Exports, []bclient.ExportEntry{{
+		Type: "image",
+		Attrs: map[string]string{
+			"push": "true",
+			"annotation-index.org.opencontainers.image.source": "https://github.com/example/app",
+		},
+	}})
+
+	pushOpts, err := toPushPlatformsOptions(opts, []string{"linux/amd64"})
+	assert.NilError(t, err)
+	assert.Equal(t, pushOpts.Exports[0].Attrs["annotation-index.org.opencontainers.image.source"], "https://github.com/example/app")
+
+	options.IndexAnnotations = map[string]string{"invalid key": "value"}
+	_, err = tested.toBuildOptions(project, project.Services["app"], options)
+	assert.ErrorContains(t, err, `invalid index annotation key "invalid key"`)
+}
+
+func TestToBuildOptionsMediaTypes(t *testing.T) {
+	tested := prepareBuildService(t)
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {
+				Name:  "app",
+				Image: "registry.example.com/app",
+				Build: &types.BuildConfig{
+					Context:   ".",
+					Platforms: []string{"linux/amd64", "linux/arm64"},
+				},
+			},
+		},
+	}
+	options := api.BuildOptions{
+		Push:       true,
+		MediaTypes: api.MediaTypesDocker,
+	}
+	opts, err := tested.toBuildOptions(project, project.Services["app"], options)
+	assert.NilError(t, err)
+	assert.Equal(t, opts.Exports[0].Attrs["oci-mediatypes"], "false")
+
+	pushOpts, err := toPushPlatformsOptions(opts, []string{"linux/amd64"})
+	assert.NilError(t, err)
+	assert.Equal(t, pushOpts.Exports[0].Attrs["oci-mediatypes"], "false")
+
+	options.MediaTypes = api.MediaTypesOCI
+	opts, err = tested.toBuildOptions(project, project.Services["app"], options)
+	assert.NilError(t, err)
+	assert.Equal(t, opts.Exports[0].Attrs["oci-mediatypes"], "true")
+
+	options.MediaTypes = ""
+	opts, err = tested.toBuildOptions(project, project.Services["app"], options)
+	assert.NilError(t, err)
+	_, ok := opts.Exports[0].Attrs["oci-mediatypes"]
+	assert.Check(t, !ok)
+
+	options.MediaTypes = "v1"
+	_, err = tested.toBuildOptions(project, project.Services["app"], options)
+	assert.ErrorContains(t, err, `invalid media types "v1"`)
+}
+
+type testAttachable struct{}
+
+func (a *testAttachable) Register(*grpc.Server) {}
+
+func TestToBuildOptionsCustomAttachables(t *testing.T) {
+	tested := prepareBuildService(t)
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {Name: "app", Build: &types.BuildConfig{Context: "."}},
+		},
+	}
+	custom := &testAttachable{}
+	opts, err := tested.toBuildOptions(project, project.Services["app"], api.BuildOptions{
+		Attachables: []session.Attachable{custom},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(opts.Session), 2)
+	assert.Equal(t, opts.Session[1], session.Attachable(custom))
+
+	// custom auth provider replaces the default one
+	auth := authprovider.NewDockerAuthProvider(&configfile.ConfigFile{}, nil)
+	opts, err = tested.toBuildOptions(project, project.Services["app"], api.BuildOptions{
+		Attachables: []session.Attachable{auth},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(opts.Session), 1)
+	assert.Equal(t, opts.Session[0], auth)
+
+	_, err = tested.toBuildOptions(project, project.Services["app"], api.BuildOptions{
+		Attachables: []session.Attachable{custom, &testAttachable{}},
+	})
+	assert.ErrorContains(t, err, "conflicting session providers of type *compose.testAttachable")
+}
+
+func TestBuildCancelSingleService(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	apiClient, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().BuildKitEnabled().Return(false, nil)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	tested := composeService{dockerCli: cli}
+
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine\n"), 0o644))
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {Name: "app", Build: &types.BuildConfig{Context: dir}},
+			"db":  {Name: "db", Build: &types.BuildConfig{Context: dir}},
+		},
+	}
+
+	cancellation := &api.BuildCancellation{}
+	started := make(chan struct{})
+	apiClient.EXPECT().ImageBuild(gomock.Any(), gomock.Any(), gomock.Any()).Times(2).
+		DoAndReturn(func(ctx context.Context, _ io.Reader, options dockertypes.ImageBuildOptions) (dockertypes.ImageBuildResponse, error) {
+			if options.Tags[0] == "test-app" {
+				close(started)
+				<-ctx.Done()
+				return dockertypes.ImageBuildResponse{}, ctx.Err()
+			}
+			return dockertypes.ImageBuildResponse{
+				Body: io.NopCloser(strings.NewReader(`{"aux":{"ID":"sha256:db"}}`)),
+			}, nil
+		})
+	go func() {
+		<-started
+		assert.Check(t, cancellation.Cancel("app"))
+	}()
+
+	_, err := tested.build(context.Background(), project, api.BuildOptions{Cancellation: cancellation}, nil)
+	assert.Check(t, errors.Is(err, api.ErrBuildCancelled))
+	assert.ErrorContains(t, err, "service(s): app")
+	assert.Check(t, !cancellation.Cancel("db"))
+}
+
+func TestToBuildOptionsCache(t *testing.T) {
+	tested := prepareBuildService(t)
+	project := &types.Project{
+		Name:       "test",
+		WorkingDir: "/project",
+		Services: types.Services{
+			"app": {
+				Name: "app",
+				Build: &types.BuildConfig{
+					Context:   ".",
+					CacheFrom: []string{"registry.example.com/app:cache", "type=local,src=.cache"},
+					CacheTo:   []string{"type=local,dest=/tmp/cache", "type=registry,ref=registry.example.com/app:cache,mode=max"},
+				},
+			},
+		},
+	}
+	opts, err := tested.toBuildOptions(project, project.Services["app"], api.BuildOptions{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, opts.CacheFrom, []bclient.CacheOptionsEntry{
+		{Type: "registry", Attrs: map[string]string{"ref": "registry.example.com/app:cache"}},
+		{Type: "local", Attrs: map[string]string{"src": "/project/.cache"}},
+	})
+	assert.DeepEqual(t, opts.CacheTo, []bclient.CacheOptionsEntry{
+		{Type: "local", Attrs: map[string]string{"dest": "/tmp/cache"}},
+		{Type: "registry", Attrs: map[string]string{"ref": "registry.example.com/app:cache", "mode": "max"}},
+	})
+}
+
+func TestMergeSSHConfigs(t *testing.T) {
+	service := types.SSHConfig{
+		{ID: "default"},
+		{ID: "github", Path: "/home/user/.ssh/github"},
+	}
+	merged := mergeSSHConfigs(service, types.SSHConfig{
+		{ID: "default", Path: "/run/agent.sock"},
+		{ID: "gitlab", Path: "/home/user/.ssh/gitlab"},
+	})
+	assert.DeepEqual(t, merged, types.SSHConfig{
+		{ID: "github", Path: "/home/user/.ssh/github"},
+		{ID: "default", Path: "/run/agent.sock"},
+		{ID: "gitlab", Path: "/home/user/.ssh/gitlab"},
+	})
+	// service configuration is left unchanged
+	assert.Equal(t, len(service), 2)
+	assert.Equal(t, service[0].Path, "")
+}
+
+func TestToBuildOptionsBuildConfig(t *testing.T) {
+	tested := prepareBuildService(t)
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {
+				Name: "app",
+				Build: &types.BuildConfig{
+					Context:    ".",
+					Target:     "prod",
+					Labels:     types.Labels{"com.example.team": "backend"},
+					Network:    "host",
+					ExtraHosts: types.HostsList{"registry.local": []string{"10.0.0.2"}},
+					ShmSize:    types.UnitBytes(256 * 1024 * 1024),
+				},
+			},
+		},
+	}
+	opts, err := tested.toBuildOptions(project, project.Services["app"], api.BuildOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, opts.Target, "prod")
+	assert.Equal(t, opts.Labels["com.example.team"], "backend")
+	assert.Equal(t, opts.NetworkMode, "host")
+	assert.DeepEqual(t, opts.ExtraHosts, []string{"registry.local:10.0.0.2"})
+	assert.Equal(t, int64(opts.ShmSize), int64(256*1024*1024))
+
+	classic := imageBuildOptions(tested.dockerCli, project, project.Services["app"], api.BuildOptions{})
+	assert.Equal(t, classic.ShmSize, int64(256*1024*1024))
+	assert.Check(t, !classic.ForceRemove)
+}
+
+func TestBuildOptionsNoCacheForceRemove(t *testing.T) {
+	tested := prepareBuildService(t)
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {
+				Name:  "app",
+				Build: &types.BuildConfig{Context: "."},
+			},
+		},
+	}
+	options := api.BuildOptions{NoCache: true, ForceRemove: true}
+	assert.NilError(t, options.Apply(project))
+
+	opts, err := tested.toBuildOptions(project, project.Services["app"], options)
+	assert.NilError(t, err)
+	assert.Check(t, opts.NoCache)
+
+	classic := imageBuildOptions(tested.dockerCli, project, project.Services["app"], options)
+	assert.Check(t, classic.NoCache)
+	assert.Check(t, classic.ForceRemove)
+}
+
+func TestDockerFilePath(t *testing.T) {
+	tests := []struct {
+		name       string
+		context    string
+		dockerfile string
+		expected   string
+	}{
+		{name: "default", context: "/project", dockerfile: "", expected: ""},
+		{name: "relative", context: "/project", dockerfile: "build/Dockerfile", expected: filepath.Join("/project", "build/Dockerfile")},
+		{name: "absolute", context: "/project", dockerfile: "/other/Dockerfile", expected: "/other/Dockerfile"},
+		{name: "windows drive", context: `C:\project`, dockerfile: `D:\other\Dockerfile`, expected: `D:\other\Dockerfile`},
+		{name: "windows drive with slashes", context: `C:\project`, dockerfile: "d:/other/Dockerfile", expected: "d:/other/Dockerfile"},
+		{name: "windows UNC", context: `C:\project`, dockerfile: `\\server\share\Dockerfile`, expected: `\\server\share\Dockerfile`},
+		{name: "git context", context: "https://github.com/docker/compose.git", dockerfile: "Dockerfile.dev", expected: "Dockerfile.dev"},
+		{name: "url context", context: "https://example.com/context.tar.gz", dockerfile: "Dockerfile.dev", expected: "Dockerfile.dev"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, dockerFilePath(tt.context, tt.dockerfile), tt.expected)
+		})
+	}
+}
+
+func TestIsRemoteContext(t *testing.T) {
+	for context, remote := range map[string]bool{
+		"https://github.com/docker/compose.git#main:pkg": true,
+		"https://example.com/context.tar.gz":             true,
+		"git@github.com:docker/compose.git":              true,
+		"github.com/docker/compose":                      true,
+		"ssh://git@example.com/docker/compose.git#v2":    true,
+		"/project/app": false,
+		"app":          false,
+	} {
+		assert.Equal(t, isRemoteContext(context), remote, context)
+	}
+
+	dir := t.TempDir()
+	local := filepath.Join(dir, "repo.git")
+	assert.NilError(t, os.Mkdir(local, 0o755))
+	assert.Check(t, !isRemoteContext(local))
+
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"ssh": {Name: "ssh", Build: &types.BuildConfig{Context: "ssh://git@example.com/docker/compose.git#v2"}},
+		},
+	}
+	assert.NilError(t, ValidateBuildConfigs(project, api.BuildOptions{}))
+	assert.Equal(t, dockerFilePath("ssh://git@example.com/docker/compose.git", "Dockerfile.dev"), "Dockerfile.dev")
+	assert.Equal(t, dockerFilePath(dir, "Dockerfile.dev"), filepath.Join(dir, "Dockerfile.dev"))
+}
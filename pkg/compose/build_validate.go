@@ -0,0 +1,115 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/moby/buildkit/session/secrets/secretsprovider"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// ValidateBuildConfigs checks build configuration of all services can be used to run a build, without
+// contacting the docker engine. All problems detected are returned at once.
+func ValidateBuildConfigs(project *types.Project, options api.BuildOptions) error {
+	var errs []error
+	for _, name := range project.ServiceNames() {
+		service := project.Services[name]
+		if service.Build == nil {
+			continue
+		}
+		for _, err := range validateBuildConfig(project, service, options) {
+			errs = append(errs, fmt.Errorf("service %q: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateBuildConfig runs the checks toBuildOptions and serviceBuildOptions apply when preparing service build
+func validateBuildConfig(project *types.Project, service types.ServiceConfig, options api.BuildOptions) []error {
+	var errs []error
+	build := service.Build
+
+	remote := isRemoteContext(build.Context)
+	if !remote {
+		if fi, err := os.Stat(build.Context); err != nil {
+			errs = append(errs, fmt.Errorf("build context %q can't be accessed: %w", build.Context, err))
+		} else if !fi.IsDir() {
+			errs = append(errs, fmt.Errorf("build context %q is not a directory", build.Context))
+		} else if build.DockerfileInline == "" {
+			dockerfile := dockerFilePath(build.Context, build.Dockerfile)
+			if dockerfile == "" {
+				dockerfile = filepath.Join(build.Context, "Dockerfile")
+			}
+			if _, err := os.Stat(dockerfile); err != nil {
+				errs = append(errs, fmt.Errorf("dockerfile %q can't be accessed: %w", dockerfile, err))
+			}
+		}
+	}
+
+	plats, err := buildPlatforms(service, options)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if _, _, err := buildCaches(project, service, options); err != nil {
+		errs = append(errs, err)
+	}
+
+	undefinedSecret := false
+	for _, secret := range build.Secrets {
+		if _, ok := project.Secrets[secret.Source]; !ok {
+			errs = append(errs, fmt.Errorf("build.secrets refers to undefined secret %q", secret.Source))
+			undefinedSecret = true
+		}
+	}
+	if !undefinedSecret {
+		if sources, err := secretSources(project, service, options.Env); err != nil {
+			errs = append(errs, err)
+		} else if _, err := secretsprovider.NewStore(sources); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(options.SSHs) > 0 || len(build.SSH) > 0 {
+		if _, err := sshAgentProvider(mergeSSHConfigs(build.SSH, options.SSHs)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := checkAdditionalContexts(project, build.AdditionalContexts); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := buildAttests(options); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := buildExports(service, options, plats); err != nil {
+		errs = append(errs, err)
+	}
+
+	if ignoreFile, ok := options.IgnoreFiles[service.Name]; ok {
+		if remote {
+			errs = append(errs, fmt.Errorf("ignore file can't be set for a remote build context"))
+		} else if err := checkIgnoreFile(ignoreFile); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,109 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestValidateBuildConfigs(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine\n"), 0o644))
+
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"valid": {Name: "valid", Build: &types.BuildConfig{Context: dir}},
+			"inline": {Name: "inline", Build: &types.BuildConfig{
+				Context:          filepath.Join(dir, "missing"),
+				DockerfileInline: "FROM alpine",
+			}},
+			"dockerfile": {Name: "dockerfile", Build: &types.BuildConfig{Context: dir, Dockerfile: "prod.Dockerfile"}},
+			"platforms":  {Name: "platforms", Build: &types.BuildConfig{Context: dir, Platforms: []string{"linux/amd64", "not/a/valid/platform"}}},
+			"secrets": {Name: "secrets", Build: &types.BuildConfig{Context: dir, Secrets: []types.ServiceSecretConfig{
+				{Source: "undefined"},
+			}}},
+			"remote": {Name: "remote", Build: &types.BuildConfig{Context: "https://github.com/docker/compose.git"}},
+			"image":  {Name: "image", Image: "alpine"},
+		},
+	}
+	err := ValidateBuildConfigs(project, api.BuildOptions{})
+	assert.ErrorContains(t, err, `service "inline": build context "`+filepath.Join(dir, "missing")+`" can't be accessed`)
+	assert.ErrorContains(t, err, `service "dockerfile": dockerfile "`+filepath.Join(dir, "prod.Dockerfile")+`" can't be accessed`)
+	assert.ErrorContains(t, err, `service "platforms": `)
+	assert.ErrorContains(t, err, `service "secrets": build.secrets refers to undefined secret "undefined"`)
+	assert.Check(t, !containsService(err.Error(), "valid"))
+	assert.Check(t, !containsService(err.Error(), "remote"))
+
+	delete(project.Services, "inline")
+	delete(project.Services, "dockerfile")
+	delete(project.Services, "platforms")
+	delete(project.Services, "secrets")
+	assert.NilError(t, ValidateBuildConfigs(project, api.BuildOptions{}))
+
+	err = ValidateBuildConfigs(project, api.BuildOptions{Env: []string{"not valid"}})
+	assert.ErrorContains(t, err, `invalid environment variable name "not valid"`)
+}
+
+func TestValidateBuildConfigsOptions(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine\n"), 0o644))
+
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {Name: "app", Build: &types.BuildConfig{
+				Context:   dir,
+				Platforms: []string{"linux/amd64", "linux/arm64"},
+				SSH:       types.SSHConfig{{ID: "deploy", Path: filepath.Join(dir, "missing_key")}},
+				AdditionalContexts: types.Mapping{
+					"assets": filepath.Join(dir, "assets"),
+					"base":   "service:undefined",
+				},
+			}},
+		},
+	}
+	options := api.BuildOptions{
+		LoadPlatform: "linux/s390x",
+		MediaTypes:   "bogus",
+		Outputs:      map[string][]string{"app": {"type=local"}},
+		IgnoreFiles:  map[string]string{"app": filepath.Join(dir, "app.dockerignore")},
+	}
+	err := ValidateBuildConfigs(project, options)
+	assert.ErrorContains(t, err, `service "app": platform "linux/s390x" is not part of the built platforms`)
+	assert.ErrorContains(t, err, `service "app": invalid media types "bogus"`)
+	assert.ErrorContains(t, err, `service "app": additional context "assets" can't be accessed`)
+	assert.ErrorContains(t, err, `service "app": ignore file "`+filepath.Join(dir, "app.dockerignore")+`" can't be accessed`)
+	assert.ErrorContains(t, err, filepath.Join(dir, "missing_key"))
+
+	// same checks apply when preparing the build
+	tested := prepareBuildService(t)
+	_, err = tested.toBuildOptions(project, project.Services["app"], options)
+	assert.ErrorContains(t, err, `platform "linux/s390x" is not part of the built platforms`)
+}
+
+func containsService(msg string, service string) bool {
+	return strings.Contains(msg, `service "`+service+`"`)
+}
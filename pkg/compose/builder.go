@@ -0,0 +1,149 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/command"
+	"github.com/jonboulle/clockwork"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+// checkBuilderCapabilities makes sure service build only relies on features the custom builder supports
+func checkBuilderCapabilities(service types.ServiceConfig, options api.BuildOptions, capabilities api.BuilderCapabilities) error {
+	if service.Build == nil {
+		return nil
+	}
+	if len(service.Build.Platforms) > 1 && !capabilities.MultiPlatform {
+		return api.NewServiceError(service.Name, api.ErrNotImplemented, "service %q requires a multi-platform build, which isn't supported by builder", service.Name)
+	}
+	if len(service.Build.Secrets) > 0 && !capabilities.Secrets {
+		return api.NewServiceError(service.Name, api.ErrNotImplemented, "service %q uses build secrets, which aren't supported by builder", service.Name)
+	}
+	if len(service.Build.SSH) > 0 && !capabilities.SSH {
+		return api.NewServiceError(service.Name, api.ErrNotImplemented, "service %q uses SSH, which isn't supported by builder", service.Name)
+	}
+	if len(service.Build.AdditionalContexts) > 0 && !capabilities.AdditionalContexts {
+		return api.NewServiceError(service.Name, api.ErrNotImplemented, "service %q uses additional contexts, which aren't supported by builder", service.Name)
+	}
+	if options.Push && !capabilities.Push {
+		return api.NewServiceError(service.Name, api.ErrNotImplemented, "service %q image can't be pushed, which isn't supported by builder", service.Name)
+	}
+	if len(options.PushPlatforms) > 0 && !(capabilities.Push && capabilities.MultiPlatform) {
+		return api.NewServiceError(service.Name, api.ErrNotImplemented, "service %q image can't be pushed for a subset of platforms, which isn't supported by builder", service.Name)
+	}
+	if _, ok := options.Outputs[service.Name]; ok && !capabilities.Outputs {
+		return api.NewServiceError(service.Name, api.ErrNotImplemented, "service %q uses custom outputs, which aren't supported by builder", service.Name)
+	}
+	if options.CacheOnly && !capabilities.CacheOnly {
+		return api.NewServiceError(service.Name, api.ErrNotImplemented, "service %q build can't only populate build cache, which isn't supported by builder", service.Name)
+	}
+	if (options.ProvenanceDir != "" || (options.Provenance != "" && options.Provenance != "false")) && !capabilities.Attestations {
+		return api.NewServiceError(service.Name, api.ErrNotImplemented, "service %q requires a provenance attestation, which isn't supported by builder", service.Name)
+	}
+	return nil
+}
+
+// buildxBuilder is the default Builder, relying on BuildKit through buildx, or on the classic builder when
+// BuildKit is disabled
+type buildxBuilder struct {
+	s *composeService
+}
+
+// NewBuildxBuilder returns the default build backend, so a custom Builder can delegate to it
+func NewBuildxBuilder(dockerCli command.Cli) api.Builder {
+	return &buildxBuilder{s: &composeService{
+		dockerCli:      dockerCli,
+		clock:          clockwork.NewRealClock(),
+		maxConcurrency: -1,
+	}}
+}
+
+func (b *buildxBuilder) Capabilities() api.BuilderCapabilities {
+	buildkitEnabled, err := b.s.dockerCli.BuildKitEnabled()
+	if err != nil || !buildkitEnabled {
+		return api.BuilderCapabilities{Push: true}
+	}
+	return api.BuilderCapabilities{
+		MultiPlatform:      true,
+		Secrets:            true,
+		SSH:                true,
+		AdditionalContexts: true,
+		Push:               true,
+		Outputs:            true,
+		CacheOnly:          true,
+		Attestations:       true,
+	}
+}
+
+func (b *buildxBuilder) BuildOne(ctx context.Context, project *types.Project, service types.ServiceConfig, options api.BuildOptions) (string, error) {
+	p := *project
+	p.Services = types.Services{}
+	for name, s := range project.Services {
+		p.Services[name] = s
+	}
+	p.Services[service.Name] = service
+	options.Services = []string{service.Name}
+	options.Deps = false
+	imageIDs, err := b.s.build(ctx, &p, options, nil)
+	if err != nil {
+		return "", err
+	}
+	return imageIDs[api.GetImageNameOrDefault(service, project.Name)], nil
+}
+
+func (b *buildxBuilder) PullOne(ctx context.Context, service types.ServiceConfig, platform string) (string, error) {
+	return b.s.pullServiceImage(ctx, service, b.s.configFile(), progress.ContextWriter(ctx), true, platform)
+}
+
+// buildWithBuilder builds service image using the custom builder
+func (s *composeService) buildWithBuilder(ctx context.Context, project *types.Project, service types.ServiceConfig, options api.BuildOptions) (string, error) {
+	return s.builder.BuildOne(ctx, project, service, options)
+}
+
+// pullWithBuilder pulls service image using the custom builder
+func (s *composeService) pullWithBuilder(ctx context.Context, service types.ServiceConfig, w progress.Writer, platform string) (string, error) {
+	w.Event(progress.Event{
+		ID:     service.Name,
+		Status: progress.Working,
+		Text:   "Pulling",
+	})
+	id, err := s.builder.PullOne(ctx, service, platform)
+	if err != nil {
+		// pull failure is only a warning when image can be built
+		status, text := progress.Error, "Error"
+		if service.Build != nil {
+			status, text = progress.Warning, "Warning"
+		}
+		w.Event(progress.Event{
+			ID:     service.Name,
+			Status: status,
+			Text:   text,
+		})
+		return "", WrapCategorisedComposeError(err, PullFailure)
+	}
+	w.Event(progress.Event{
+		ID:     service.Name,
+		Status: progress.Done,
+		Text:   "Pulled",
+	})
+	return id, nil
+}
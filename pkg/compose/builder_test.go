@@ -0,0 +1,194 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	moby "github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+type fakeBuilder struct {
+	capabilities api.BuilderCapabilities
+	mux          sync.Mutex
+	built        []string
+	pulled       []string
+	buildConfigs map[string]types.BuildConfig
+	buildArgs    map[string]types.MappingWithEquals
+}
+
+func (b *fakeBuilder) Capabilities() api.BuilderCapabilities {
+	return b.capabilities
+}
+
+func (b *fakeBuilder) BuildOne(_ context.Context, _ *types.Project, service types.ServiceConfig, options api.BuildOptions) (string, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.built = append(b.built, service.Name)
+	if b.buildConfigs == nil {
+		b.buildConfigs = map[string]types.BuildConfig{}
+		b.buildArgs = map[string]types.MappingWithEquals{}
+	}
+	b.buildConfigs[service.Name] = *service.Build
+	b.buildArgs[service.Name] = options.Args
+	return "sha256:built-" + service.Name, nil
+}
+
+func (b *fakeBuilder) PullOne(_ context.Context, service types.ServiceConfig, _ string) (string, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.pulled = append(b.pulled, service.Name)
+	return "sha256:pulled-" + service.Name, nil
+}
+
+func TestEnsureImagesExistsWithCustomBuilder(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	builder := &fakeBuilder{}
+	tested := composeService{
+		dockerCli: cli,
+	}
+	tested.UseBuilder(builder)
+
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {Name: "app", Build: &types.BuildConfig{Context: "."}, CustomLabels: types.Labels{}},
+			"db":  {Name: "db", Image: "registry.example.com/db:1.0", CustomLabels: types.Labels{}},
+		},
+	}
+	notFound := errdefs.NotFound(errors.New("no such image"))
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), gomock.Any()).Return(moby.ImageInspect{}, nil, notFound).Times(2)
+
+	err := tested.ensureImagesExists(context.Background(), project, ensureImagesOptions{Build: &api.BuildOptions{}, QuietPull: true})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, builder.pulled, []string{"db"})
+	assert.DeepEqual(t, builder.built, []string{"app"})
+	assert.Equal(t, project.Services["app"].CustomLabels[api.ImageDigestLabel], "sha256:built-app")
+	assert.Equal(t, project.Services["db"].CustomLabels[api.ImageDigestLabel], "sha256:pulled-db")
+
+	project.Services["app"].Build.Platforms = []string{"linux/amd64", "linux/arm64"}
+	_, err = tested.build(context.Background(), project, api.BuildOptions{}, nil)
+	assert.ErrorContains(t, err, `service "app" requires a multi-platform build, which isn't supported by builder`)
+}
+
+func TestBuildWithOptions(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	_, cli := prepareMocks(mockCtrl)
+	builder := &fakeBuilder{}
+	tested := composeService{
+		dockerCli: cli,
+	}
+	tested.UseBuilder(builder)
+
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app":    {Name: "app", Build: &types.BuildConfig{Context: "."}},
+			"worker": {Name: "worker", Build: &types.BuildConfig{Context: "."}},
+			"db":     {Name: "db", Image: "postgres"},
+		},
+	}
+	err := tested.Build(context.Background(), project, api.BuildOptions{
+		Services: []string{"app"},
+		NoCache:  true,
+		Pull:     true,
+		Args:     types.NewMappingWithEquals([]string{"VERSION=1.2"}),
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, builder.built, []string{"app"})
+	assert.Check(t, builder.buildConfigs["app"].NoCache)
+	assert.Check(t, builder.buildConfigs["app"].Pull)
+	assert.Equal(t, *builder.buildArgs["app"]["VERSION"], "1.2")
+}
+
+func TestBuildRejectsOptionsUnsupportedByBuilder(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	_, cli := prepareMocks(mockCtrl)
+	builder := &fakeBuilder{}
+	tested := composeService{
+		dockerCli: cli,
+	}
+	tested.UseBuilder(builder)
+
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {Name: "app", Image: "registry.example.com/app", Build: &types.BuildConfig{Context: "."}},
+		},
+	}
+	tests := []struct {
+		options api.BuildOptions
+		err     string
+	}{
+		{options: api.BuildOptions{Push: true}, err: `service "app" image can't be pushed`},
+		{options: api.BuildOptions{Push: true, PushPlatforms: []string{"linux/amd64"}}, err: `service "app" image can't be pushed`},
+		{options: api.BuildOptions{Outputs: map[string][]string{"app": {"type=local,dest=out"}}}, err: `service "app" uses custom outputs`},
+		{options: api.BuildOptions{CacheOnly: true}, err: `service "app" build can't only populate build cache`},
+		{options: api.BuildOptions{Provenance: "mode=max"}, err: `service "app" requires a provenance attestation`},
+	}
+	for _, tt := range tests {
+		_, err := tested.build(context.Background(), project, tt.options, nil)
+		assert.ErrorContains(t, err, tt.err)
+		assert.Check(t, api.IsErrNotImplemented(err))
+	}
+	assert.Equal(t, len(builder.built), 0)
+
+	builder.capabilities = api.BuilderCapabilities{Push: true}
+	_, err := tested.build(context.Background(), project, api.BuildOptions{Push: true}, nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, builder.built, []string{"app"})
+}
+
+func TestBuildCacheOnlyDoesNotRecordImages(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// no ImageTag expectation, so tagging would fail the test
+	_, cli := prepareMocks(mockCtrl)
+	builder := &fakeBuilder{capabilities: api.BuilderCapabilities{CacheOnly: true}}
+	tested := composeService{
+		dockerCli: cli,
+	}
+	tested.UseBuilder(builder)
+
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {Name: "app", Image: "registry.example.com/app", Build: &types.BuildConfig{Context: "."}},
+		},
+	}
+	imageIDs, err := tested.build(context.Background(), project, api.BuildOptions{CacheOnly: true, DigestTag: true}, nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, builder.built, []string{"app"})
+	assert.Equal(t, len(imageIDs), 0)
+}
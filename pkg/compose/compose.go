@@ -36,6 +36,7 @@ import (
 	"github.com/docker/cli/cli/flags"
 	"github.com/docker/cli/cli/streams"
 	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/prompt"
 	moby "github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/swarm"
@@ -68,6 +69,14 @@ type composeService struct {
 	clock          clockwork.Clock
 	maxConcurrency int
 	dryRun         bool
+	// builder replaces BuildKit or classic builder when set
+	builder api.Builder
+	// verifier checks services images before containers are created, when set
+	verifier api.ImageVerifier
+	// offline prevents any network access, so only local images and build cache are used
+	offline bool
+	// prompter confirms destructive operations, when set
+	prompter api.Prompter
 }
 
 // Close releases any connections/resources held by the underlying clients.
@@ -97,6 +106,26 @@ func (s *composeService) MaxConcurrency(i int) {
 	s.maxConcurrency = i
 }
 
+func (s *composeService) UseBuilder(builder api.Builder) {
+	s.builder = builder
+}
+
+func (s *composeService) UseImageVerifier(verifier api.ImageVerifier) {
+	s.verifier = verifier
+}
+
+func (s *composeService) UsePrompter(prompter api.Prompter) {
+	s.prompter = prompter
+}
+
+// confirm asks user to confirm msg, using configured prompter or falling back to an interactive prompt on stdin
+func (s *composeService) confirm(msg string) (bool, error) {
+	if s.prompter != nil {
+		return s.prompter.Confirm(msg, false)
+	}
+	return prompt.NewPrompt(s.stdin(), s.stdout()).Confirm(msg, false)
+}
+
 func (s *composeService) DryRunMode(ctx context.Context, dryRun bool) (context.Context, error) {
 	s.dryRun = dryRun
 	if dryRun {
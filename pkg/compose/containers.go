@@ -18,7 +18,6 @@ package compose
 
 import (
 	"context"
-	"fmt"
 	"sort"
 	"strconv"
 
@@ -89,14 +88,18 @@ func (s *composeService) getSpecifiedContainer(ctx context.Context, projectName
 	}
 	if len(containers) < 1 {
 		if containerIndex > 0 {
-			return moby.Container{}, fmt.Errorf("service %q is not running container #%d", serviceName, containerIndex)
+			return moby.Container{}, api.NewServiceError(serviceName, api.ErrNotFound, "service %q is not running container #%d", serviceName, containerIndex)
 		}
-		return moby.Container{}, fmt.Errorf("service %q is not running", serviceName)
+		return moby.Container{}, api.NewServiceError(serviceName, api.ErrNotFound, "service %q is not running", serviceName)
 	}
 	sort.Slice(containers, func(i, j int) bool {
 		x, _ := strconv.Atoi(containers[i].Labels[api.ContainerNumberLabel])
 		y, _ := strconv.Atoi(containers[j].Labels[api.ContainerNumberLabel])
-		return x < y
+		if x != y {
+			return x < y
+		}
+		// prefer service replicas over one-off containers sharing the same number
+		return isNotOneOff(containers[i]) && !isNotOneOff(containers[j])
 	})
 	container := containers[0]
 	return container, nil
@@ -118,6 +121,12 @@ func isRunning() containerPredicate {
 	}
 }
 
+func isPaused() containerPredicate {
+	return func(c moby.Container) bool {
+		return c.State == ContainerPaused
+	}
+}
+
 func isNotService(services ...string) containerPredicate {
 	return func(c moby.Container) bool {
 		service := c.Labels[api.ServiceLabel]
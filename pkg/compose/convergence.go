@@ -227,6 +227,26 @@ func getScale(config types.ServiceConfig) (int, error) {
 	return scale, nil
 }
 
+// checkContainerNames rejects custom container names which can't be honored, either because the service is scaled
+// or because another service declares the same name
+func checkContainerNames(project *types.Project) error {
+	names := map[string]string{}
+	for _, name := range project.ServiceNames() {
+		service := project.Services[name]
+		if service.ContainerName == "" {
+			continue
+		}
+		if _, err := getScale(service); err != nil {
+			return err
+		}
+		if other, ok := names[service.ContainerName]; ok {
+			return fmt.Errorf("services %q and %q declare the same container name %q", other, name, service.ContainerName)
+		}
+		names[service.ContainerName] = name
+	}
+	return nil
+}
+
 // resolveServiceReferences replaces reference to another service with reference to an actual container
 func (c *convergence) resolveServiceReferences(service *types.ServiceConfig) error {
 	err := c.resolveVolumeFrom(service)
@@ -343,8 +363,15 @@ func containerReasonEvents(containers Containers, eventFunc func(string, string)
 // ServiceConditionRunningOrHealthy is a service condition on status running or healthy
 const ServiceConditionRunningOrHealthy = "running_or_healthy"
 
-//nolint:gocyclo
 func (s *composeService) waitDependencies(ctx context.Context, project *types.Project, dependant string, dependencies types.DependsOnConfig, containers Containers) error {
+	return s.awaitDependencies(ctx, project, dependant, dependencies, containers, nil)
+}
+
+// awaitDependencies waits for dependencies like waitDependencies, and notifies onPending, if set, of the
+// dependencies which didn't satisfy their depends_on condition before ctx was done
+//
+//nolint:gocyclo
+func (s *composeService) awaitDependencies(ctx context.Context, project *types.Project, dependant string, dependencies types.DependsOnConfig, containers Containers, onPending func(dep string)) error {
 	eg, _ := errgroup.WithContext(ctx)
 	w := progress.ContextWriter(ctx)
 	for dep, config := range dependencies {
@@ -366,17 +393,27 @@ func (s *composeService) waitDependencies(ctx context.Context, project *types.Pr
 
 		dep, config := dep, config
 		eg.Go(func() error {
+			// dependency didn't satisfy its condition before context was done
+			stillPending := func() {
+				if onPending != nil {
+					onPending(dep)
+				}
+			}
 			ticker := time.NewTicker(500 * time.Millisecond)
 			defer ticker.Stop()
 			for {
 				select {
 				case <-ticker.C:
 				case <-ctx.Done():
+					stillPending()
 					return nil
 				}
 				switch config.Condition {
 				case ServiceConditionRunningOrHealthy:
 					healthy, err := s.isServiceHealthy(ctx, waitingFor, true)
+					if err != nil && ctx.Err() != nil {
+						stillPending()
+					}
 					if err != nil {
 						if !config.Required {
 							w.Events(containerReasonEvents(waitingFor, progress.SkippedEvent, fmt.Sprintf("optional dependency %q is not running or is unhealthy", dep)))
@@ -391,6 +428,9 @@ func (s *composeService) waitDependencies(ctx context.Context, project *types.Pr
 					}
 				case types.ServiceConditionHealthy:
 					healthy, err := s.isServiceHealthy(ctx, waitingFor, false)
+					if err != nil && ctx.Err() != nil {
+						stillPending()
+					}
 					if err != nil {
 						if !config.Required {
 							w.Events(containerReasonEvents(waitingFor, progress.SkippedEvent, fmt.Sprintf("optional dependency %q failed to start", dep)))
@@ -406,6 +446,9 @@ func (s *composeService) waitDependencies(ctx context.Context, project *types.Pr
 					}
 				case types.ServiceConditionCompletedSuccessfully:
 					exited, code, err := s.isServiceCompleted(ctx, waitingFor)
+					if err != nil && ctx.Err() != nil {
+						stillPending()
+					}
 					if err != nil {
 						return err
 					}
@@ -437,6 +480,30 @@ func (s *composeService) waitDependencies(ctx context.Context, project *types.Pr
 	return eg.Wait()
 }
 
+// waitDependenciesWithTimeout waits for dependencies like waitDependencies, but fails if they don't satisfy
+// their depends_on conditions within timeout. A zero timeout waits without limit.
+func (s *composeService) waitDependenciesWithTimeout(ctx context.Context, project *types.Project, dependant string, dependencies types.DependsOnConfig, containers Containers, timeout time.Duration) error {
+	if timeout <= 0 {
+		return s.waitDependencies(ctx, project, dependant, dependencies, containers)
+	}
+	withTimeout, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var (
+		pending    []string
+		pendingMux sync.Mutex
+	)
+	err := s.awaitDependencies(withTimeout, project, dependant, dependencies, containers, func(dep string) {
+		pendingMux.Lock()
+		defer pendingMux.Unlock()
+		pending = append(pending, dep)
+	})
+	if len(pending) > 0 && errors.Is(withTimeout.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		sort.Strings(pending)
+		return fmt.Errorf("service %q dependencies did not become ready after %s: %s", dependant, timeout, strings.Join(pending, ", "))
+	}
+	return err
+}
+
 func shouldWaitForDependency(serviceName string, dependencyConfig types.ServiceDependency, project *types.Project) (bool, error) {
 	if dependencyConfig.Condition == types.ServiceConditionStarted {
 		// already managed by InDependencyOrder
@@ -757,12 +824,12 @@ func (s *composeService) isServiceCompleted(ctx context.Context, containers Cont
 	return false, 0, nil
 }
 
-func (s *composeService) startService(ctx context.Context, project *types.Project, service types.ServiceConfig, containers Containers, wait bool) error {
+func (s *composeService) startService(ctx context.Context, project *types.Project, service types.ServiceConfig, containers Containers, wait bool, dependenciesTimeout time.Duration) error {
 	if service.Deploy != nil && service.Deploy.Replicas != nil && *service.Deploy.Replicas == 0 {
 		return nil
 	}
 
-	err := s.waitDependencies(ctx, project, service.Name, service.DependsOn, containers)
+	err := s.waitDependenciesWithTimeout(ctx, project, service.Name, service.DependsOn, containers, dependenciesTimeout)
 	if err != nil {
 		return err
 	}
@@ -771,7 +838,7 @@ func (s *composeService) startService(ctx context.Context, project *types.Projec
 		if service.GetScale() == 0 {
 			return nil
 		}
-		return fmt.Errorf("service %q has no container to start", service.Name)
+		return api.NewServiceError(service.Name, api.ErrNotFound, "service %q has no container to start", service.Name)
 	}
 
 	w := progress.ContextWriter(ctx)
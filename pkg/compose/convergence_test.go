@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/docker/cli/cli/config/configfile"
@@ -58,6 +59,22 @@ func TestContainerName(t *testing.T) {
 	assert.Error(t, err, fmt.Sprintf(doubledContainerNameWarning, s.Name, s.ContainerName))
 }
 
+func TestCheckContainerNames(t *testing.T) {
+	project := &types.Project{
+		Services: types.Services{
+			"db":  {Name: "db", ContainerName: "database"},
+			"web": {Name: "web"},
+		},
+	}
+	assert.NilError(t, checkContainerNames(project))
+
+	project.Services["cache"] = types.ServiceConfig{Name: "cache", ContainerName: "database"}
+	assert.Error(t, checkContainerNames(project), `services "cache" and "db" declare the same container name "database"`)
+
+	project.Services["cache"] = types.ServiceConfig{Name: "cache", ContainerName: "cache", Scale: intPtr(2)}
+	assert.Error(t, checkContainerNames(project), fmt.Sprintf(doubledContainerNameWarning, "cache", "cache"))
+}
+
 func intPtr(i int) *int {
 	return &i
 }
@@ -254,6 +271,60 @@ func TestWaitDependencies(t *testing.T) {
 		}
 		assert.NilError(t, tested.waitDependencies(context.Background(), &project, "", dependencies, nil))
 	})
+	t.Run("should fail when dependency is not healthy within timeout", func(t *testing.T) {
+		dbService := types.ServiceConfig{Name: "db", Scale: intPtr(1)}
+		project := types.Project{Name: strings.ToLower(testProject), Services: types.Services{
+			"db": dbService,
+		}}
+		dependencies := types.DependsOnConfig{
+			"db": {Condition: types.ServiceConditionHealthy, Required: true},
+		}
+		apiClient.EXPECT().ContainerInspect(gomock.Any(), "123").Return(moby.ContainerJSON{
+			ContainerJSONBase: &moby.ContainerJSONBase{
+				Name:  "/db-1",
+				State: &moby.ContainerState{Status: "running", Health: &moby.Health{Status: moby.Starting}},
+			},
+			Config: &containerType.Config{Healthcheck: &containerType.HealthConfig{}},
+		}, nil).AnyTimes()
+		containers := Containers{testContainer("db", "123", false)}
+
+		err := tested.waitDependenciesWithTimeout(context.Background(), &project, "web", dependencies, containers, time.Second)
+		assert.Error(t, err, `service "web" dependencies did not become ready after 1s: db`)
+	})
+	t.Run("should only report dependencies which are not ready after timeout", func(t *testing.T) {
+		project := types.Project{Name: strings.ToLower(testProject), Services: types.Services{
+			"db":    {Name: "db", Scale: intPtr(1)},
+			"cache": {Name: "cache", Scale: intPtr(1)},
+		}}
+		dependencies := types.DependsOnConfig{
+			"db":    {Condition: types.ServiceConditionHealthy, Required: true},
+			"cache": {Condition: types.ServiceConditionHealthy, Required: true},
+		}
+		apiClient.EXPECT().ContainerInspect(gomock.Any(), "456").Return(moby.ContainerJSON{
+			ContainerJSONBase: &moby.ContainerJSONBase{
+				Name:  "/cache-1",
+				State: &moby.ContainerState{Status: "running", Health: &moby.Health{Status: moby.Healthy}},
+			},
+			Config: &containerType.Config{Healthcheck: &containerType.HealthConfig{}},
+		}, nil).AnyTimes()
+		containers := Containers{testContainer("db", "123", false), testContainer("cache", "456", false)}
+
+		err := tested.waitDependenciesWithTimeout(context.Background(), &project, "web", dependencies, containers, time.Second)
+		assert.Error(t, err, `service "web" dependencies did not become ready after 1s: db`)
+	})
+	t.Run("should not fail when cancelled", func(t *testing.T) {
+		project := types.Project{Name: strings.ToLower(testProject), Services: types.Services{
+			"db": {Name: "db", Scale: intPtr(1)},
+		}}
+		dependencies := types.DependsOnConfig{
+			"db": {Condition: types.ServiceConditionHealthy, Required: true},
+		}
+		containers := Containers{testContainer("db", "123", false)}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		assert.NilError(t, tested.waitDependencies(ctx, &project, "web", dependencies, containers))
+	})
 }
 
 func TestCreateMobyContainer(t *testing.T) {
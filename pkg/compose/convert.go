@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	compose "github.com/compose-spec/compose-go/v2/types"
@@ -89,11 +90,11 @@ func (s *composeService) ToMobyHealthCheck(ctx context.Context, check *compose.H
 	}, nil
 }
 
-// ToSeconds convert into seconds
+// ToSeconds convert into seconds, rounding up so a sub-second grace period doesn't kill container immediately
 func ToSeconds(d *compose.Duration) *int {
 	if d == nil {
 		return nil
 	}
-	s := int(time.Duration(*d).Seconds())
+	s := int(math.Ceil(time.Duration(*d).Seconds()))
 	return &s
 }
@@ -0,0 +1,76 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/container"
+	"gotest.tools/v3/assert"
+)
+
+func TestToMobyHealthCheck(t *testing.T) {
+	s := &composeService{}
+	interval := types.Duration(30 * time.Second)
+	timeout := types.Duration(5 * time.Second)
+	startPeriod := types.Duration(time.Minute)
+	retries := uint64(3)
+	check := &types.HealthCheckConfig{
+		Test:        types.HealthCheckTest{"CMD", "curl", "-f", "http://localhost"},
+		Interval:    &interval,
+		Timeout:     &timeout,
+		StartPeriod: &startPeriod,
+		Retries:     &retries,
+	}
+
+	healthcheck, err := s.ToMobyHealthCheck(context.Background(), check)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, healthcheck, &container.HealthConfig{
+		Test:        []string{"CMD", "curl", "-f", "http://localhost"},
+		Interval:    30 * time.Second,
+		Timeout:     5 * time.Second,
+		StartPeriod: time.Minute,
+		Retries:     3,
+	})
+
+	healthcheck, err = s.ToMobyHealthCheck(context.Background(), &types.HealthCheckConfig{Disable: true})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, healthcheck.Test, []string{"NONE"})
+
+	healthcheck, err = s.ToMobyHealthCheck(context.Background(), nil)
+	assert.NilError(t, err)
+	assert.Check(t, healthcheck == nil)
+}
+
+func TestToSeconds(t *testing.T) {
+	assert.Check(t, ToSeconds(nil) == nil)
+	for _, tc := range []struct {
+		duration time.Duration
+		expected int
+	}{
+		{duration: 0, expected: 0},
+		{duration: 500 * time.Millisecond, expected: 1},
+		{duration: 10 * time.Second, expected: 10},
+		{duration: 90500 * time.Millisecond, expected: 91},
+	} {
+		d := types.Duration(tc.duration)
+		assert.Equal(t, *ToSeconds(&d), tc.expected)
+	}
+}
@@ -123,7 +123,8 @@ func (s *composeService) listContainersTargetedForCopy(ctx context.Context, proj
 	var containers Containers
 	var err error
 	switch {
-	case index > 0:
+	case index > 0 || direction == fromService:
+		// copying from a service uses its first replica, unless one is selected
 		container, err := s.getSpecifiedContainer(ctx, projectName, oneOffExclude, true, serviceName, index)
 		if err != nil {
 			return nil, err
@@ -136,11 +137,7 @@ func (s *composeService) listContainersTargetedForCopy(ctx context.Context, proj
 		}
 
 		if len(containers) < 1 {
-			return nil, fmt.Errorf("no container found for service %q", serviceName)
-		}
-		if direction == fromService {
-			return containers[:1], err
-
+			return nil, api.NewServiceError(serviceName, api.ErrNotFound, "no container found for service %q", serviceName)
 		}
 		return containers, err
 	}
@@ -0,0 +1,75 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	moby "github.com/docker/docker/api/types"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestListContainersTargetedForCopy(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+	name := strings.ToLower(testProject)
+
+	replica := func(id, number string) moby.Container {
+		c := testContainer("web", id, false)
+		c.Labels[api.ContainerNumberLabel] = number
+		return c
+	}
+	apiClient.EXPECT().ContainerList(gomock.Any(), gomock.Any()).
+		Return([]moby.Container{replica("c2", "2"), replica("c1", "1"), replica("c3", "3")}, nil).AnyTimes()
+
+	containers, err := tested.listContainersTargetedForCopy(context.Background(), name, 0, fromService, "web")
+	assert.NilError(t, err)
+	assert.Equal(t, len(containers), 1)
+	assert.Equal(t, containers[0].ID, "c1")
+
+	containers, err = tested.listContainersTargetedForCopy(context.Background(), name, 0, toService, "web")
+	assert.NilError(t, err)
+	assert.Equal(t, len(containers), 3)
+}
+
+func TestSplitCpArg(t *testing.T) {
+	tests := []struct {
+		arg       string
+		container string
+		path      string
+	}{
+		{arg: "web:/etc/hosts", container: "web", path: "/etc/hosts"},
+		{arg: "/tmp/file", path: "/tmp/file"},
+		{arg: "./file:name.txt", path: "./file:name.txt"},
+		{arg: "file.txt", path: "file.txt"},
+	}
+	for _, tt := range tests {
+		container, path := splitCpArg(tt.arg)
+		assert.Equal(t, container, tt.container, tt.arg)
+		assert.Equal(t, path, tt.path, tt.arg)
+	}
+}
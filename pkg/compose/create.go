@@ -25,6 +25,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -64,6 +65,10 @@ type createConfigs struct {
 }
 
 func (s *composeService) Create(ctx context.Context, project *types.Project, createOpts api.CreateOptions) error {
+	project, err := withProfiles(project, createOpts.Profiles)
+	if err != nil {
+		return err
+	}
 	return progress.RunWithTitle(ctx, func(ctx context.Context) error {
 		return s.create(ctx, project, createOpts)
 	}, s.stdinfo(), "Creating")
@@ -74,17 +79,48 @@ func (s *composeService) create(ctx context.Context, project *types.Project, opt
 		options.Services = project.ServiceNames()
 	}
 
+	if err := checkContainerNames(project); err != nil {
+		return err
+	}
+
 	var observedState Containers
 	observedState, err := s.getContainers(ctx, project.Name, oneOffInclude, true)
 	if err != nil {
 		return err
 	}
 
-	err = s.ensureImagesExists(ctx, project, options.Build, options.QuietPull)
+	if options.ImagesLock != "" && options.VerifyImagesLock {
+		// images are pulled by digest, so only the locked ones can be used
+		lock, err := loadImagesLock(options.ImagesLock)
+		if err != nil {
+			return err
+		}
+		if err := lock.pin(project); err != nil {
+			return err
+		}
+	}
+
+	err = s.ensureImagesExists(ctx, project, ensureImagesOptions{
+		Build:         options.Build,
+		QuietPull:     options.QuietPull,
+		Timeout:       options.ImagesTimeout,
+		Retries:       options.PullRetries,
+		RetryNotFound: options.PullRetryNotFound,
+	})
 	if err != nil {
 		return err
 	}
 
+	if options.ImagesLock != "" && !options.VerifyImagesLock {
+		lock, err := s.resolveImagesLock(ctx, project)
+		if err != nil {
+			return err
+		}
+		if err := writeImagesLock(options.ImagesLock, lock); err != nil {
+			return err
+		}
+	}
+
 	prepareNetworks(project)
 
 	if err := s.ensureNetworks(ctx, project.Networks); err != nil {
@@ -154,7 +190,7 @@ func (s *composeService) getCreateConfigs(ctx context.Context,
 	inherit *moby.Container,
 	opts createOptions,
 ) (createConfigs, error) {
-	labels, err := s.prepareLabels(opts.Labels, service, number)
+	labels, err := s.prepareLabels(p.Name, opts.Labels, service, number)
 	if err != nil {
 		return createConfigs{}, err
 	}
@@ -389,12 +425,12 @@ func createEndpointSettings(p *types.Project, service types.ServiceConfig, servi
 		macAddress = config.MacAddress
 	}
 	return &network.EndpointSettings{
-		Aliases:     getAliases(p, service, serviceIndex, networkKey, useNetworkAliases),
-		Links:       links,
-		IPAddress:   ipv4Address,
-		IPv6Gateway: ipv6Address,
-		IPAMConfig:  ipam,
-		MacAddress:  macAddress,
+		Aliases:           getAliases(p, service, serviceIndex, networkKey, useNetworkAliases),
+		Links:             links,
+		IPAddress:         ipv4Address,
+		GlobalIPv6Address: ipv6Address,
+		IPAMConfig:        ipam,
+		MacAddress:        macAddress,
 	}
 }
 
@@ -410,22 +446,22 @@ func parseSecurityOpts(p *types.Project, securityOpts []string) ([]string, bool,
 			unconfined = true
 			continue
 		}
-		con := strings.SplitN(opt, "=", 2)
-		if len(con) == 1 && con[0] != "no-new-privileges" {
-			if strings.Contains(opt, ":") {
-				con = strings.SplitN(opt, ":", 2)
-			} else {
-				return securityOpts, false, fmt.Errorf("Invalid security-opt: %q", opt)
-			}
+		k, v, ok := strings.Cut(opt, "=")
+		if !ok && k != "no-new-privileges" {
+			k, v, ok = strings.Cut(opt, ":")
+		}
+		if (!ok || v == "") && k != "no-new-privileges" {
+			// "no-new-privileges" is the only option that does not require a value
+			return securityOpts, false, fmt.Errorf("Invalid security-opt: %q", opt)
 		}
-		if con[0] == "seccomp" && con[1] != "unconfined" {
-			f, err := os.ReadFile(p.RelativePath(con[1]))
+		if k == "seccomp" && v != "unconfined" && v != "builtin" {
+			f, err := os.ReadFile(p.RelativePath(v))
 			if err != nil {
-				return securityOpts, false, fmt.Errorf("opening seccomp profile (%s) failed: %w", con[1], err)
+				return securityOpts, false, fmt.Errorf("opening seccomp profile (%s) failed: %w", v, err)
 			}
 			b := bytes.NewBuffer(nil)
 			if err := json.Compact(b, f); err != nil {
-				return securityOpts, false, fmt.Errorf("compacting json for seccomp profile (%s) failed: %w", con[1], err)
+				return securityOpts, false, fmt.Errorf("compacting json for seccomp profile (%s) failed: %w", v, err)
 			}
 			parsed = append(parsed, fmt.Sprintf("seccomp=%s", b.Bytes()))
 		} else {
@@ -436,7 +472,20 @@ func parseSecurityOpts(p *types.Project, securityOpts []string) ([]string, bool,
 	return parsed, unconfined, nil
 }
 
-func (s *composeService) prepareLabels(labels types.Labels, service types.ServiceConfig, number int) (map[string]string, error) {
+func (s *composeService) prepareLabels(projectName string, labels types.Labels, service types.ServiceConfig, number int) (map[string]string, error) {
+	// set labels compose relies on to track containers, if not already set by the caller
+	defaults := map[string]string{
+		api.ProjectLabel: projectName,
+		api.ServiceLabel: service.Name,
+		api.VersionLabel: api.ComposeVersion,
+		api.OneoffLabel:  "False",
+	}
+	for k, v := range defaults {
+		if _, ok := labels[k]; !ok {
+			labels[k] = v
+		}
+	}
+
 	hash, err := ServiceHash(service)
 	if err != nil {
 		return nil, err
@@ -523,8 +572,13 @@ func getRestartPolicy(service types.ServiceConfig) container.RestartPolicy {
 		if policy.MaxAttempts != nil {
 			attempts = int(*policy.MaxAttempts)
 		}
+		condition := policy.Condition
+		if condition == "" {
+			// compose specification defines `any` as the default condition
+			condition = "any"
+		}
 		restart = container.RestartPolicy{
-			Name:              mapRestartPolicyCondition(policy.Condition),
+			Name:              mapRestartPolicyCondition(condition),
 			MaximumRetryCount: attempts,
 		}
 	}
@@ -631,6 +685,10 @@ func toUlimits(m map[string]*types.UlimitsConfig) []*units.Ulimit {
 			Soft: int64(soft),
 		})
 	}
+	// keep container configuration stable across runs
+	sort.Slice(ulimits, func(i, j int) bool {
+		return ulimits[i].Name < ulimits[j].Name
+	})
 	return ulimits
 }
 
@@ -645,9 +703,14 @@ func setReservations(reservations *types.Resource, resources *container.Resource
 	}
 
 	for _, device := range reservations.Devices {
+		count := int(device.Count)
+		if count == 0 && len(device.IDs) == 0 {
+			// neither count nor device_ids set, all devices are reserved
+			count = -1
+		}
 		resources.DeviceRequests = append(resources.DeviceRequests, container.DeviceRequest{
 			Capabilities: [][]string{device.Capabilities},
-			Count:        int(device.Count),
+			Count:        count,
 			DeviceIDs:    device.IDs,
 			Driver:       device.Driver,
 		})
@@ -715,8 +778,7 @@ func buildContainerPorts(s types.ServiceConfig) nat.PortSet {
 		ports[p] = struct{}{}
 	}
 	for _, p := range s.Ports {
-		p := nat.Port(fmt.Sprintf("%d/%s", p.Target, p.Protocol))
-		ports[p] = struct{}{}
+		ports[containerPort(p)] = struct{}{}
 	}
 	return ports
 }
@@ -724,7 +786,7 @@ func buildContainerPorts(s types.ServiceConfig) nat.PortSet {
 func buildContainerPortBindingOptions(s types.ServiceConfig) nat.PortMap {
 	bindings := nat.PortMap{}
 	for _, port := range s.Ports {
-		p := nat.Port(fmt.Sprintf("%d/%s", port.Target, port.Protocol))
+		p := containerPort(port)
 		binding := nat.PortBinding{
 			HostIP:   port.HostIP,
 			HostPort: port.Published,
@@ -734,6 +796,15 @@ func buildContainerPortBindingOptions(s types.ServiceConfig) nat.PortMap {
 	return bindings
 }
 
+// containerPort returns the container port for a service port, using tcp protocol when none is set
+func containerPort(port types.ServicePortConfig) nat.Port {
+	protocol := port.Protocol
+	if protocol == "" {
+		protocol = "tcp"
+	}
+	return nat.Port(fmt.Sprintf("%d/%s", port.Target, protocol))
+}
+
 func getDependentServiceFromMode(mode string) string {
 	if strings.HasPrefix(
 		mode,
@@ -891,10 +962,6 @@ func buildContainerConfigMounts(p types.Project, s types.ServiceConfig) ([]mount
 			target = configsBaseDir + config.Target
 		}
 
-		if config.UID != "" || config.GID != "" || config.Mode != nil {
-			logrus.Warn("config `uid`, `gid` and `mode` are not supported, they will be ignored")
-		}
-
 		definedConfig := p.Configs[config.Source]
 		if definedConfig.External {
 			return nil, fmt.Errorf("unsupported external config %s", definedConfig.Name)
@@ -911,6 +978,10 @@ func buildContainerConfigMounts(p types.Project, s types.ServiceConfig) ([]mount
 			continue
 		}
 
+		if config.UID != "" || config.GID != "" || config.Mode != nil {
+			logrus.Warn("config `uid`, `gid` and `mode` are not supported for file based sources, they will be ignored")
+		}
+
 		bindMount, err := buildMount(p, types.ServiceVolumeConfig{
 			Type:     types.VolumeTypeBind,
 			Source:   definedConfig.File,
@@ -941,10 +1012,6 @@ func buildContainerSecretMounts(p types.Project, s types.ServiceConfig) ([]mount
 			target = secretsDir + secret.Target
 		}
 
-		if secret.UID != "" || secret.GID != "" || secret.Mode != nil {
-			logrus.Warn("secrets `uid`, `gid` and `mode` are not supported, they will be ignored")
-		}
-
 		definedSecret := p.Secrets[secret.Source]
 		if definedSecret.External {
 			return nil, fmt.Errorf("unsupported external secret %s", definedSecret.Name)
@@ -961,6 +1028,10 @@ func buildContainerSecretMounts(p types.Project, s types.ServiceConfig) ([]mount
 			continue
 		}
 
+		if secret.UID != "" || secret.GID != "" || secret.Mode != nil {
+			logrus.Warn("secrets `uid`, `gid` and `mode` are not supported for file based sources, they will be ignored")
+		}
+
 		mnt, err := buildMount(p, types.ServiceVolumeConfig{
 			Type:     types.VolumeTypeBind,
 			Source:   definedSecret.File,
@@ -1174,7 +1245,7 @@ func (s *composeService) resolveOrCreateNetwork(ctx context.Context, n *types.Ne
 	}
 
 	var ipam *network.IPAM
-	if n.Ipam.Config != nil {
+	if n.Ipam.Driver != "" || len(n.Ipam.Config) > 0 {
 		var config []network.IPAMConfig
 		for _, pool := range n.Ipam.Config {
 			config = append(config, network.IPAMConfig{
@@ -1200,23 +1271,6 @@ func (s *composeService) resolveOrCreateNetwork(ctx context.Context, n *types.Ne
 		EnableIPv6:     n.EnableIPv6,
 	}
 
-	if n.Ipam.Driver != "" || len(n.Ipam.Config) > 0 {
-		createOpts.IPAM = &network.IPAM{}
-	}
-
-	if n.Ipam.Driver != "" {
-		createOpts.IPAM.Driver = n.Ipam.Driver
-	}
-
-	for _, ipamConfig := range n.Ipam.Config {
-		config := network.IPAMConfig{
-			Subnet:     ipamConfig.Subnet,
-			IPRange:    ipamConfig.IPRange,
-			Gateway:    ipamConfig.Gateway,
-			AuxAddress: ipamConfig.AuxiliaryAddresses,
-		}
-		createOpts.IPAM.Config = append(createOpts.IPAM.Config, config)
-	}
 	networkEventName := fmt.Sprintf("Network %s", n.Name)
 	w := progress.ContextWriter(ctx)
 	w.Event(progress.CreatingEvent(networkEventName))
@@ -1308,6 +1362,9 @@ func (s *composeService) ensureVolume(ctx context.Context, volume types.VolumeCo
 	if ok && p != project {
 		logrus.Warnf("volume %q already exists but was created for project %q (expected %q). Use `external: true` to use an existing volume", volume.Name, p, project)
 	}
+	if volume.Driver != "" && inspected.Driver != volume.Driver {
+		logrus.Warnf("volume %q already exists with driver %q (expected %q). Remove volume to apply configuration", volume.Name, inspected.Driver, volume.Driver)
+	}
 	return nil
 }
 
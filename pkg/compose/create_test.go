@@ -17,11 +17,23 @@
 package compose
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"sort"
 	"testing"
 
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"go.uber.org/mock/gomock"
 	"gotest.tools/v3/assert/cmp"
 
 	"github.com/docker/compose/v2/pkg/api"
@@ -85,6 +97,23 @@ func TestServiceImageName(t *testing.T) {
 	assert.Equal(t, api.GetImageNameOrDefault(composetypes.ServiceConfig{Name: "aService"}, "myProject"), "myProject-aService")
 }
 
+func TestPrepareLabels(t *testing.T) {
+	s := &composeService{}
+	service := composetypes.ServiceConfig{Name: "web"}
+
+	labels, err := s.prepareLabels("myProject", composetypes.Labels{}, service, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, labels[api.ProjectLabel], "myProject")
+	assert.Equal(t, labels[api.ServiceLabel], "web")
+	assert.Equal(t, labels[api.OneoffLabel], "False")
+	assert.Equal(t, labels[api.ContainerNumberLabel], "1")
+
+	labels, err = s.prepareLabels("myProject", composetypes.Labels{api.OneoffLabel: "True"}, service, 2)
+	assert.NilError(t, err)
+	assert.Equal(t, labels[api.OneoffLabel], "True")
+	assert.Equal(t, labels[api.ContainerNumberLabel], "2")
+}
+
 func TestPrepareNetworkLabels(t *testing.T) {
 	project := composetypes.Project{
 		Name:     "myProject",
@@ -263,3 +292,394 @@ func TestDefaultNetworkSettings(t *testing.T) {
 		assert.Check(t, cmp.Nil(networkConfig))
 	})
 }
+
+func TestEnsureVolume(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+	ctx := context.Background()
+
+	t.Run("creates missing volume", func(t *testing.T) {
+		data := composetypes.VolumeConfig{
+			Name:       "myProject_data",
+			Driver:     "local",
+			DriverOpts: map[string]string{"type": "tmpfs"},
+			Labels:     composetypes.Labels{api.ProjectLabel: "myProject"},
+		}
+		apiClient.EXPECT().VolumeInspect(ctx, "myProject_data").Return(volume.Volume{}, errdefs.NotFound(errors.New("not found")))
+		apiClient.EXPECT().VolumeCreate(ctx, volume.CreateOptions{
+			Name:       "myProject_data",
+			Driver:     "local",
+			DriverOpts: map[string]string{"type": "tmpfs"},
+			Labels:     map[string]string{api.ProjectLabel: "myProject"},
+		}).Return(volume.Volume{}, nil)
+		assert.NilError(t, tested.ensureVolume(ctx, data, "myProject"))
+	})
+
+	t.Run("fails on missing external volume", func(t *testing.T) {
+		apiClient.EXPECT().VolumeInspect(ctx, "shared").Return(volume.Volume{}, errdefs.NotFound(errors.New("not found")))
+		err := tested.ensureVolume(ctx, composetypes.VolumeConfig{Name: "shared", External: true}, "myProject")
+		assert.Error(t, err, `external volume "shared" not found`)
+	})
+
+	t.Run("warns on driver mismatch", func(t *testing.T) {
+		hook := logrustest.NewGlobal()
+		defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+		apiClient.EXPECT().VolumeInspect(ctx, "myProject_nfs").Return(volume.Volume{
+			Name:   "myProject_nfs",
+			Driver: "local",
+			Labels: map[string]string{api.ProjectLabel: "myProject"},
+		}, nil)
+		err := tested.ensureVolume(ctx, composetypes.VolumeConfig{Name: "myProject_nfs", Driver: "nfs"}, "myProject")
+		assert.NilError(t, err)
+		assert.Equal(t, len(hook.Entries), 1)
+		assert.Equal(t, hook.LastEntry().Message, `volume "myProject_nfs" already exists with driver "local" (expected "nfs"). Remove volume to apply configuration`)
+	})
+}
+
+func TestCreateEndpointSettings(t *testing.T) {
+	project := &composetypes.Project{Name: "myProject"}
+	service := composetypes.ServiceConfig{
+		Name: "web",
+		Networks: map[string]*composetypes.ServiceNetworkConfig{
+			"backend": {
+				Aliases:     []string{"api"},
+				Ipv4Address: "172.28.0.10",
+				Ipv6Address: "2001:db8::10",
+			},
+		},
+	}
+
+	settings := createEndpointSettings(project, service, 1, "backend", nil, true)
+	assert.DeepEqual(t, settings, &network.EndpointSettings{
+		Aliases:           []string{"myProject-web-1", "web", "api"},
+		IPAddress:         "172.28.0.10",
+		GlobalIPv6Address: "2001:db8::10",
+		IPAMConfig: &network.EndpointIPAMConfig{
+			IPv4Address: "172.28.0.10",
+			IPv6Address: "2001:db8::10",
+		},
+	})
+}
+
+func TestResolveOrCreateNetworkIPAM(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+	ctx := context.Background()
+
+	n := &composetypes.NetworkConfig{
+		Name:       "myProject_backend",
+		Driver:     "bridge",
+		DriverOpts: map[string]string{"com.docker.network.bridge.name": "br-backend"},
+		Internal:   true,
+		Attachable: true,
+		Ipam: composetypes.IPAMConfig{
+			Config: []*composetypes.IPAMPool{
+				{Subnet: "172.28.0.0/16", IPRange: "172.28.5.0/24", Gateway: "172.28.5.254"},
+			},
+		},
+		Labels: composetypes.Labels{
+			api.ProjectLabel: "myProject",
+			api.NetworkLabel: "backend",
+		},
+	}
+	apiClient.EXPECT().NetworkInspect(ctx, "myProject_backend", moby.NetworkInspectOptions{}).
+		Return(moby.NetworkResource{}, errdefs.NotFound(errors.New("not found")))
+	apiClient.EXPECT().NetworkList(ctx, gomock.Any()).Return(nil, nil)
+	apiClient.EXPECT().NetworkCreate(ctx, "myProject_backend", moby.NetworkCreate{
+		CheckDuplicate: true,
+		Labels:         map[string]string{api.ProjectLabel: "myProject", api.NetworkLabel: "backend"},
+		Driver:         "bridge",
+		Options:        map[string]string{"com.docker.network.bridge.name": "br-backend"},
+		Internal:       true,
+		Attachable:     true,
+		IPAM: &network.IPAM{
+			Config: []network.IPAMConfig{
+				{Subnet: "172.28.0.0/16", IPRange: "172.28.5.0/24", Gateway: "172.28.5.254"},
+			},
+		},
+	}).Return(moby.NetworkCreateResponse{}, nil)
+
+	assert.NilError(t, tested.resolveOrCreateNetwork(ctx, n))
+}
+
+func TestGetRestartPolicy(t *testing.T) {
+	maxAttempts := uint64(3)
+	for _, tc := range []struct {
+		name     string
+		service  composetypes.ServiceConfig
+		expected container.RestartPolicy
+	}{
+		{
+			name:     "no restart",
+			service:  composetypes.ServiceConfig{},
+			expected: container.RestartPolicy{},
+		},
+		{
+			name:     "restart with retries",
+			service:  composetypes.ServiceConfig{Restart: "on-failure:5"},
+			expected: container.RestartPolicy{Name: container.RestartPolicyOnFailure, MaximumRetryCount: 5},
+		},
+		{
+			name:     "restart unless stopped",
+			service:  composetypes.ServiceConfig{Restart: "unless-stopped"},
+			expected: container.RestartPolicy{Name: container.RestartPolicyUnlessStopped},
+		},
+		{
+			name: "deploy restart policy overrides restart",
+			service: composetypes.ServiceConfig{
+				Restart: "always",
+				Deploy: &composetypes.DeployConfig{
+					RestartPolicy: &composetypes.RestartPolicy{Condition: "on-failure", MaxAttempts: &maxAttempts},
+				},
+			},
+			expected: container.RestartPolicy{Name: container.RestartPolicyOnFailure, MaximumRetryCount: 3},
+		},
+		{
+			name: "deploy restart policy defaults to any",
+			service: composetypes.ServiceConfig{
+				Deploy: &composetypes.DeployConfig{RestartPolicy: &composetypes.RestartPolicy{}},
+			},
+			expected: container.RestartPolicy{Name: container.RestartPolicyAlways},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.DeepEqual(t, getRestartPolicy(tc.service), tc.expected)
+		})
+	}
+}
+
+func TestBuildContainerPortBindingOptions(t *testing.T) {
+	service := composetypes.ServiceConfig{
+		Name: "web",
+		Ports: []composetypes.ServicePortConfig{
+			{Target: 80, Published: "8080", Protocol: "tcp", Mode: "host"},
+			{Target: 80, Published: "8080", HostIP: "::1", Protocol: "tcp"},
+			{Target: 53, Published: "5353", HostIP: "127.0.0.1", Protocol: "udp"},
+			{Target: 443},
+		},
+	}
+
+	assert.DeepEqual(t, buildContainerPorts(service), nat.PortSet{
+		"80/tcp":  {},
+		"53/udp":  {},
+		"443/tcp": {},
+	})
+	assert.DeepEqual(t, buildContainerPortBindingOptions(service), nat.PortMap{
+		"80/tcp": {
+			{HostPort: "8080"},
+			{HostIP: "::1", HostPort: "8080"},
+		},
+		"53/udp":  {{HostIP: "127.0.0.1", HostPort: "5353"}},
+		"443/tcp": {{}},
+	})
+}
+
+func TestSetReservationsDevices(t *testing.T) {
+	var resources container.Resources
+	setReservations(&composetypes.Resource{
+		MemoryBytes: composetypes.UnitBytes(512 * 1024 * 1024),
+		Devices: []composetypes.DeviceRequest{
+			{Driver: "nvidia", Capabilities: []string{"gpu"}, Count: -1},
+			{Capabilities: []string{"gpu", "utility"}, IDs: []string{"0", "3"}},
+			{Driver: "nvidia", Capabilities: []string{"gpu"}, Count: 2},
+			{Capabilities: []string{"tpu"}},
+		},
+	}, &resources)
+
+	assert.Equal(t, resources.MemoryReservation, int64(512*1024*1024))
+	assert.DeepEqual(t, resources.DeviceRequests, []container.DeviceRequest{
+		{Driver: "nvidia", Capabilities: [][]string{{"gpu"}}, Count: -1},
+		{Capabilities: [][]string{{"gpu", "utility"}}, DeviceIDs: []string{"0", "3"}},
+		{Driver: "nvidia", Capabilities: [][]string{{"gpu"}}, Count: 2},
+		{Capabilities: [][]string{{"tpu"}}, Count: -1},
+	})
+}
+
+func TestGetDeployResources(t *testing.T) {
+	pids := int64(100)
+	service := composetypes.ServiceConfig{
+		Name:      "web",
+		MemLimit:  composetypes.UnitBytes(256 * 1024 * 1024),
+		CPUS:      0.5,
+		PidsLimit: 50,
+		Ulimits: map[string]*composetypes.UlimitsConfig{
+			"nproc":  {Single: 65535},
+			"nofile": {Soft: 20000, Hard: 40000},
+		},
+		Deploy: &composetypes.DeployConfig{
+			Resources: composetypes.Resources{
+				Limits: &composetypes.Resource{
+					NanoCPUs:    "1.5",
+					MemoryBytes: composetypes.UnitBytes(512 * 1024 * 1024),
+					Pids:        pids,
+				},
+			},
+		},
+	}
+
+	resources := getDeployResources(service)
+	assert.Equal(t, resources.Memory, int64(512*1024*1024))
+	assert.Equal(t, resources.NanoCPUs, int64(1.5e9))
+	assert.Equal(t, *resources.PidsLimit, int64(100))
+	assert.DeepEqual(t, resources.Ulimits, []*units.Ulimit{
+		{Name: "nofile", Soft: 20000, Hard: 40000},
+		{Name: "nproc", Soft: 65535, Hard: 65535},
+	})
+
+	service.Deploy = nil
+	resources = getDeployResources(service)
+	assert.Equal(t, resources.Memory, int64(256*1024*1024))
+	assert.Equal(t, resources.NanoCPUs, int64(0.5e9))
+	assert.Equal(t, *resources.PidsLimit, int64(50))
+}
+
+func TestGetCreateConfigsServiceOverrides(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	apiClient.EXPECT().DaemonHost().Return("").AnyTimes()
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), gomock.Any()).Return(moby.ImageInspect{}, nil, nil).AnyTimes()
+	// force `RuntimeVersion` to fetch fresh version
+	runtimeVersion = runtimeVersionCache{}
+	apiClient.EXPECT().ServerVersion(gomock.Any()).Return(moby.Version{APIVersion: "1.43"}, nil).AnyTimes()
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	initProcess := true
+	service := composetypes.ServiceConfig{
+		Name:       "web",
+		Image:      "nginx",
+		Init:       &initProcess,
+		User:       "1000:1000",
+		WorkingDir: "/app",
+		Entrypoint: composetypes.ShellCommand{"/docker-entrypoint.sh"},
+		Command:    composetypes.ShellCommand{"nginx", "-g", "daemon off;"},
+		Hostname:   "web",
+		DomainName: "example.com",
+		MacAddress: "02:42:ac:11:00:02",
+		StdinOpen:  true,
+		Tty:        true,
+	}
+	project := &composetypes.Project{
+		Name:     "test",
+		Services: composetypes.Services{"web": service},
+	}
+
+	cfgs, err := tested.getCreateConfigs(context.Background(), project, service, 1, nil, createOptions{
+		Labels: composetypes.Labels{},
+	})
+	assert.NilError(t, err)
+	assert.Check(t, cfgs.Host.Init != nil && *cfgs.Host.Init)
+	assert.Equal(t, cfgs.Container.User, "1000:1000")
+	assert.Equal(t, cfgs.Container.WorkingDir, "/app")
+	assert.DeepEqual(t, []string(cfgs.Container.Entrypoint), []string{"/docker-entrypoint.sh"})
+	assert.DeepEqual(t, []string(cfgs.Container.Cmd), []string{"nginx", "-g", "daemon off;"})
+	assert.Equal(t, cfgs.Container.Hostname, "web")
+	assert.Equal(t, cfgs.Container.Domainname, "example.com")
+	assert.Equal(t, cfgs.Container.MacAddress, "02:42:ac:11:00:02")
+	assert.Check(t, cfgs.Container.OpenStdin)
+	assert.Check(t, cfgs.Container.Tty)
+	assert.Check(t, !cfgs.Container.StdinOnce, "stdin is only kept open once when attached")
+
+	// an explicitly empty entrypoint or command resets the image ones
+	service.Entrypoint = composetypes.ShellCommand{}
+	service.Command = composetypes.ShellCommand{}
+	cfgs, err = tested.getCreateConfigs(context.Background(), project, service, 1, nil, createOptions{
+		Labels: composetypes.Labels{},
+	})
+	assert.NilError(t, err)
+	assert.Check(t, cfgs.Container.Entrypoint != nil && len(cfgs.Container.Entrypoint) == 0)
+	assert.Check(t, cfgs.Container.Cmd != nil && len(cfgs.Container.Cmd) == 0)
+}
+
+func TestParseSecurityOpts(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "seccomp.json"), []byte("{\n  \"defaultAction\": \"SCMP_ACT_ALLOW\"\n}\n"), 0o600)
+	assert.NilError(t, err)
+	project := &composetypes.Project{WorkingDir: dir}
+
+	parsed, unconfined, err := parseSecurityOpts(project, []string{
+		"no-new-privileges",
+		"apparmor=unconfined",
+		"label:disable",
+		"seccomp=builtin",
+		"seccomp:seccomp.json",
+		"systempaths=unconfined",
+	})
+	assert.NilError(t, err)
+	assert.Check(t, unconfined)
+	assert.DeepEqual(t, parsed, []string{
+		"no-new-privileges",
+		"apparmor=unconfined",
+		"label:disable",
+		"seccomp=builtin",
+		`seccomp={"defaultAction":"SCMP_ACT_ALLOW"}`,
+	})
+
+	_, _, err = parseSecurityOpts(project, []string{"apparmor"})
+	assert.ErrorContains(t, err, `Invalid security-opt: "apparmor"`)
+
+	_, _, err = parseSecurityOpts(project, []string{"label="})
+	assert.ErrorContains(t, err, `Invalid security-opt: "label="`)
+
+	_, _, err = parseSecurityOpts(project, []string{"seccomp=missing.json"})
+	assert.ErrorContains(t, err, "opening seccomp profile (missing.json) failed")
+}
+
+func TestGetCreateConfigsNetworking(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	apiClient.EXPECT().DaemonHost().Return("").AnyTimes()
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), gomock.Any()).Return(moby.ImageInspect{}, nil, nil).AnyTimes()
+	// force `RuntimeVersion` to fetch fresh version
+	runtimeVersion = runtimeVersionCache{}
+	apiClient.EXPECT().ServerVersion(gomock.Any()).Return(moby.Version{APIVersion: "1.43"}, nil).AnyTimes()
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	service := composetypes.ServiceConfig{
+		Name:       "web",
+		Image:      "nginx",
+		Sysctls:    composetypes.Mapping{"net.core.somaxconn": "1024"},
+		ExtraHosts: composetypes.HostsList{"somehost": {"162.242.195.82"}},
+		DNS:        composetypes.StringList{"8.8.8.8"},
+		DNSSearch:  composetypes.StringList{"example.com"},
+		DNSOpts:    []string{"use-vc"},
+	}
+	project := &composetypes.Project{
+		Name:     "test",
+		Services: composetypes.Services{"web": service},
+	}
+
+	for _, mode := range []string{"host", "none", "container:0123456789ab"} {
+		service.NetworkMode = mode
+		cfgs, err := tested.getCreateConfigs(context.Background(), project, service, 1, nil, createOptions{
+			Labels: composetypes.Labels{},
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, cfgs.Host.NetworkMode, container.NetworkMode(mode))
+		assert.Check(t, cfgs.Network == nil)
+		assert.DeepEqual(t, cfgs.Host.Sysctls, map[string]string{"net.core.somaxconn": "1024"})
+		assert.DeepEqual(t, cfgs.Host.ExtraHosts, []string{"somehost:162.242.195.82"})
+		assert.DeepEqual(t, cfgs.Host.DNS, []string{"8.8.8.8"})
+		assert.DeepEqual(t, cfgs.Host.DNSSearch, []string{"example.com"})
+		assert.DeepEqual(t, cfgs.Host.DNSOptions, []string{"use-vc"})
+	}
+}
@@ -279,7 +279,7 @@ func NewGraph(project *types.Project, initialStatus ServiceStatus) (*Graph, erro
 				if api.IsNotFoundError(err) {
 					ds, err := project.GetDisabledService(name)
 					if err == nil {
-						return nil, fmt.Errorf("service %s is required by %s but is disabled. Can be enabled by profiles %s", name, s.Name, ds.Profiles)
+						return nil, api.NewServiceError(name, api.ErrInvalidConfig, "service %s is required by %s but is disabled. Can be enabled by profiles %s", name, s.Name, ds.Profiles)
 					}
 				}
 				return nil, err
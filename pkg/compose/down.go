@@ -38,15 +38,51 @@ import (
 type downOp func() error
 
 func (s *composeService) Down(ctx context.Context, projectName string, options api.DownOptions) error {
+	if s.prompter != nil {
+		if msg := downConfirmMessage(projectName, options); msg != "" {
+			confirm, err := s.confirm(msg)
+			if err != nil {
+				return err
+			}
+			if !confirm {
+				return api.ErrCanceled
+			}
+		}
+	}
 	return progress.Run(ctx, func(ctx context.Context) error {
 		return s.down(ctx, strings.ToLower(projectName), options)
 	}, s.stdinfo())
 }
 
+// downConfirmMessage returns the message to confirm data removal by down, or an empty string if nothing needs confirmation
+func downConfirmMessage(projectName string, options api.DownOptions) string {
+	var resources []string
+	if options.Volumes {
+		resources = append(resources, "volumes")
+	}
+	switch ImagePruneMode(options.Images) {
+	case ImagePruneAll:
+		resources = append(resources, "all images used by services")
+	case ImagePruneLocal:
+		resources = append(resources, "images built for services without a custom tag")
+	}
+	if len(resources) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Going to remove %s of project %q, continue?", strings.Join(resources, " and "), strings.ToLower(projectName))
+}
+
 func (s *composeService) down(ctx context.Context, projectName string, options api.DownOptions) error { //nolint:gocyclo
 	w := progress.ContextWriter(ctx)
 	resourceToRemove := false
 
+	// reject unsupported images mode before any resource gets removed
+	switch ImagePruneMode(options.Images) {
+	case ImagePruneNone, ImagePruneLocal, ImagePruneAll:
+	default:
+		return fmt.Errorf("unsupported image prune mode: %s", options.Images)
+	}
+
 	include := oneOffExclude
 	if options.RemoveOrphans {
 		include = oneOffInclude
@@ -141,7 +177,7 @@ func (s *composeService) ensureVolumesDown(ctx context.Context, project *types.P
 		}
 		volumeName := vol.Name
 		ops = append(ops, func() error {
-			return s.removeVolume(ctx, volumeName, w)
+			return s.removeVolume(ctx, volumeName, project.Name, w)
 		})
 	}
 	return ops
@@ -260,10 +296,29 @@ func (s *composeService) removeImage(ctx context.Context, image string, w progre
 	return err
 }
 
-func (s *composeService) removeVolume(ctx context.Context, id string, w progress.Writer) error {
+func (s *composeService) removeVolume(ctx context.Context, id string, projectName string, w progress.Writer) error {
 	resource := fmt.Sprintf("Volume %s", id)
+	inspected, err := s.apiClient().VolumeInspect(ctx, id)
+	if errdefs.IsNotFound(err) {
+		w.Event(progress.NewEvent(resource, progress.Done, "Warning: No resource found to remove"))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// a volume with same name might exist but belong to another project or have been created by user, don't clobber it
+	p, ok := inspected.Labels[api.ProjectLabel]
+	if !ok {
+		w.Event(progress.NewEvent(resource, progress.Warning, "Resource was not created by Docker Compose, not removed"))
+		return nil
+	}
+	if p != projectName {
+		w.Event(progress.NewEvent(resource, progress.Warning, fmt.Sprintf("Resource belongs to project %q, not removed", p)))
+		return nil
+	}
+
 	w.Event(progress.NewEvent(resource, progress.Working, "Removing"))
-	err := s.apiClient().VolumeRemove(ctx, id, true)
+	err = s.apiClient().VolumeRemove(ctx, id, true)
 	if err == nil {
 		w.Event(progress.NewEvent(resource, progress.Done, "Removed"))
 		return nil
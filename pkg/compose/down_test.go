@@ -36,6 +36,7 @@ import (
 
 	compose "github.com/docker/compose/v2/pkg/api"
 	"github.com/docker/compose/v2/pkg/mocks"
+	"github.com/docker/compose/v2/pkg/prompt"
 )
 
 func TestDown(t *testing.T) {
@@ -95,6 +96,39 @@ func TestDown(t *testing.T) {
 	assert.NilError(t, err)
 }
 
+func TestDownInvalidImagesMode(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	_, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	// no container is listed nor removed
+	err := tested.down(context.Background(), strings.ToLower(testProject), compose.DownOptions{Images: "dangling"})
+	assert.Error(t, err, "unsupported image prune mode: dangling")
+}
+
+func TestDownConfirmDeclined(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	_, cli := prepareMocks(mockCtrl)
+	prompter := prompt.NewMockUI(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+		prompter:  prompter,
+	}
+
+	prompter.EXPECT().Confirm(`Going to remove volumes and all images used by services of project "testproject", continue?`, false).
+		Return(false, nil)
+
+	// no container is listed nor removed
+	err := tested.Down(context.Background(), strings.ToLower(testProject), compose.DownOptions{Volumes: true, Images: "all"})
+	assert.Assert(t, compose.IsErrCanceled(err))
+}
+
 func TestDownRemoveOrphans(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -170,12 +204,44 @@ func TestDownRemoveVolumes(t *testing.T) {
 	api.EXPECT().ContainerStop(gomock.Any(), "123", containerType.StopOptions{}).Return(nil)
 	api.EXPECT().ContainerRemove(gomock.Any(), "123", containerType.RemoveOptions{Force: true, RemoveVolumes: true}).Return(nil)
 
+	api.EXPECT().VolumeInspect(gomock.Any(), "myProject_volume").
+		Return(volume.Volume{Name: "myProject_volume", Labels: map[string]string{compose.ProjectLabel: strings.ToLower(testProject)}}, nil)
 	api.EXPECT().VolumeRemove(gomock.Any(), "myProject_volume", true).Return(nil)
 
 	err := tested.Down(context.Background(), strings.ToLower(testProject), compose.DownOptions{Volumes: true})
 	assert.NilError(t, err)
 }
 
+func TestDownKeepsForeignVolumes(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	project := &types.Project{
+		Name: strings.ToLower(testProject),
+		Volumes: types.Volumes{
+			"data":  {Name: "shared_data"},
+			"cache": {Name: "user_cache"},
+		},
+	}
+
+	apiClient.EXPECT().ContainerList(gomock.Any(), projectFilterListOpt(false)).Return(nil, nil)
+	apiClient.EXPECT().NetworkList(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+
+	// same volume names, but created by another project or by user: none get removed
+	apiClient.EXPECT().VolumeInspect(gomock.Any(), "shared_data").
+		Return(volume.Volume{Name: "shared_data", Labels: map[string]string{compose.ProjectLabel: "other"}}, nil)
+	apiClient.EXPECT().VolumeInspect(gomock.Any(), "user_cache").
+		Return(volume.Volume{Name: "user_cache"}, nil)
+
+	err := tested.Down(context.Background(), strings.ToLower(testProject), compose.DownOptions{Project: project, Volumes: true})
+	assert.NilError(t, err)
+}
+
 func TestDownRemoveImages(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
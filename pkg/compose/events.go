@@ -32,6 +32,8 @@ func (s *composeService) Events(ctx context.Context, projectName string, options
 	projectName = strings.ToLower(projectName)
 	events, errors := s.apiClient().Events(ctx, moby.EventsOptions{
 		Filters: filters.NewArgs(projectFilter(projectName)),
+		Since:   options.Since,
+		Until:   options.Until,
 	})
 	for {
 		select {
@@ -0,0 +1,83 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	moby "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	compose "github.com/docker/compose/v2/pkg/api"
+)
+
+func TestEvents(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+	projectName := strings.ToLower(testProject)
+
+	messages := make(chan events.Message, 4)
+	errs := make(chan error, 1)
+	api.EXPECT().Events(gomock.Any(), moby.EventsOptions{
+		Filters: filters.NewArgs(projectFilter(projectName)),
+		Since:   "10m",
+	}).Return(messages, errs)
+
+	messages <- events.Message{Type: events.NetworkEventType, Action: events.ActionCreate}
+	messages <- events.Message{Type: events.ContainerEventType, Action: events.ActionStart, Actor: events.Actor{
+		ID:         "run",
+		Attributes: map[string]string{compose.ServiceLabel: "web", compose.OneoffLabel: "True"},
+	}}
+	messages <- events.Message{Type: events.ContainerEventType, Action: events.ActionStart, Actor: events.Actor{
+		ID:         "db1",
+		Attributes: map[string]string{compose.ServiceLabel: "db"},
+	}}
+	messages <- events.Message{Type: events.ContainerEventType, Action: events.ActionHealthStatusHealthy, TimeNano: 42, Actor: events.Actor{
+		ID:         "web1",
+		Attributes: map[string]string{compose.ServiceLabel: "web", compose.ProjectLabel: projectName, "image": "nginx"},
+	}}
+
+	var received []compose.Event
+	err := tested.Events(context.Background(), projectName, compose.EventsOptions{
+		Services: []string{"web"},
+		Since:    "10m",
+		Consumer: func(event compose.Event) error {
+			received = append(received, event)
+			return io.EOF
+		},
+	})
+	assert.Equal(t, err, io.EOF)
+	assert.DeepEqual(t, received, []compose.Event{{
+		Timestamp:  time.Unix(0, 42),
+		Service:    "web",
+		Container:  "web1",
+		Status:     "health_status: healthy",
+		Attributes: map[string]string{"image": "nginx"},
+	}})
+}
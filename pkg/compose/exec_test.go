@@ -0,0 +1,65 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	moby "github.com/docker/docker/api/types"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	compose "github.com/docker/compose/v2/pkg/api"
+)
+
+func TestGetExecTarget(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+	projectName := strings.ToLower(testProject)
+
+	oneOff := testContainer("service1", "run", true)
+	oneOff.Labels[compose.ContainerNumberLabel] = "1"
+	replica1 := testContainer("service1", "123", false)
+	replica1.Labels[compose.ContainerNumberLabel] = "1"
+	replica2 := testContainer("service1", "456", false)
+	replica2.Labels[compose.ContainerNumberLabel] = "2"
+
+	api.EXPECT().ContainerList(gomock.Any(), containerType.ListOptions{
+		Filters: filters.NewArgs(projectFilter(projectName), serviceFilter("service1"), hasConfigHashLabel()),
+	}).Return([]moby.Container{replica2, oneOff, replica1}, nil)
+
+	target, err := tested.getExecTarget(context.Background(), projectName, compose.RunOptions{Service: "service1"})
+	assert.NilError(t, err)
+	assert.Equal(t, target.ID, "123")
+
+	api.EXPECT().ContainerList(gomock.Any(), containerType.ListOptions{
+		Filters: filters.NewArgs(projectFilter(projectName), serviceFilter("service1"), hasConfigHashLabel(), containerNumberFilter(3)),
+	}).Return(nil, nil)
+
+	_, err = tested.getExecTarget(context.Background(), projectName, compose.RunOptions{Service: "service1", Index: 3})
+	assert.Error(t, err, `service "service1" is not running container #3`)
+	assert.Assert(t, compose.IsNotFoundError(err))
+}
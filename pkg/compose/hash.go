@@ -30,7 +30,10 @@ func ServiceHash(o types.ServiceConfig) (string, error) {
 	o.PullPolicy = ""
 	o.Scale = nil
 	if o.Deploy != nil {
-		o.Deploy.Replicas = nil
+		// copy deploy config, so the service definition keeps its replicas
+		deploy := *o.Deploy
+		deploy.Replicas = nil
+		o.Deploy = &deploy
 	}
 
 	bytes, err := json.Marshal(o)
@@ -31,6 +31,23 @@ func TestServiceHash(t *testing.T) {
 	assert.Equal(t, hash1, hash2)
 }
 
+func TestServiceHashKeepsReplicas(t *testing.T) {
+	service := serviceConfig(3)
+	_, err := ServiceHash(service)
+	assert.NilError(t, err)
+	assert.Equal(t, *service.Deploy.Replicas, 3)
+}
+
+func TestServiceHashConfigChange(t *testing.T) {
+	service := serviceConfig(1)
+	hash1, err := ServiceHash(service)
+	assert.NilError(t, err)
+	service.Environment = types.NewMappingWithEquals([]string{"DEBUG=true"})
+	hash2, err := ServiceHash(service)
+	assert.NilError(t, err)
+	assert.Check(t, hash1 != hash2)
+}
+
 func serviceConfig(replicas int) types.ServiceConfig {
 	return types.ServiceConfig{
 		Scale: &replicas,
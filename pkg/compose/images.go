@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/distribution/reference"
 	moby "github.com/docker/docker/api/types"
@@ -105,12 +106,14 @@ func (s *composeService) getImages(ctx context.Context, images []string) (map[st
 					tag = tagged.Tag()
 				}
 			}
+			created, _ := time.Parse(time.RFC3339Nano, inspect.Created)
 			l.Lock()
 			summary[img] = api.ImageSummary{
 				ID:         inspect.ID,
 				Repository: repository,
 				Tag:        tag,
 				Size:       inspect.Size,
+				Created:    created,
 			}
 			l.Unlock()
 			return nil
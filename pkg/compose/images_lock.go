@@ -0,0 +1,147 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// imagesLock records the registry digest resolved for each service image, so that subsequent runs can
+// make sure they use the exact same images
+type imagesLock struct {
+	Services map[string]imagesLockEntry `json:"services"`
+}
+
+type imagesLockEntry struct {
+	Image string `json:"image"`
+	// Digest is the repository digest of image, empty for services built locally
+	Digest string `json:"digest,omitempty"`
+	// Build is set for services built locally, which can't be pinned to a registry digest
+	Build bool `json:"build,omitempty"`
+}
+
+func loadImagesLock(path string) (imagesLock, error) {
+	var lock imagesLock
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return lock, err
+	}
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return lock, fmt.Errorf("invalid images lock file %s: %w", path, err)
+	}
+	return lock, nil
+}
+
+func writeImagesLock(path string, lock imagesLock) error {
+	content, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+// pin sets services images to the digest recorded by lock, so the locked images get pulled. Services which are
+// not locked, or which image or build changed since lock was recorded, make pin fail
+func (l imagesLock) pin(project *types.Project) error {
+	var mismatches []string
+	for name, service := range project.Services {
+		image := api.GetImageNameOrDefault(service, project.Name)
+		built := service.Build != nil
+		locked, ok := l.Services[name]
+		switch {
+		case !ok:
+			mismatches = append(mismatches, fmt.Sprintf("service %q is not locked", name))
+		case locked.Image != image || locked.Build != built:
+			mismatches = append(mismatches, fmt.Sprintf("service %q uses %s, locked to %s", name, describeLockEntry(image, built), describeLockEntry(locked.Image, locked.Build)))
+		case !built:
+			named, err := reference.ParseNormalizedNamed(image)
+			if err != nil {
+				return err
+			}
+			dgst, err := digest.Parse(locked.Digest)
+			if err != nil {
+				return fmt.Errorf("invalid digest locked for service %q: %w", name, err)
+			}
+			pinned, err := reference.WithDigest(named, dgst)
+			if err != nil {
+				return err
+			}
+			service.Image = reference.FamiliarString(pinned)
+			project.Services[name] = service
+		}
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+	sort.Strings(mismatches)
+	return fmt.Errorf("images don't match lock file: %s", strings.Join(mismatches, ", "))
+}
+
+func describeLockEntry(image string, built bool) string {
+	if built {
+		return image + " (built)"
+	}
+	return image
+}
+
+// resolveImagesLock collects the repository digest of images resolved by ensureImagesExists for project services.
+// Services with a build section are recorded as built, as their image doesn't come from a registry
+func (s *composeService) resolveImagesLock(ctx context.Context, project *types.Project) (imagesLock, error) {
+	lock := imagesLock{Services: map[string]imagesLockEntry{}}
+	for name, service := range project.Services {
+		image := api.GetImageNameOrDefault(service, project.Name)
+		if service.Build != nil {
+			lock.Services[name] = imagesLockEntry{Image: image, Build: true}
+			continue
+		}
+		named, err := reference.ParseNormalizedNamed(image)
+		if err != nil {
+			return lock, err
+		}
+		inspect, _, err := s.apiClient().ImageInspectWithRaw(ctx, image)
+		if err != nil {
+			return lock, err
+		}
+		var repoDigest string
+		for _, rd := range inspect.RepoDigests {
+			ref, err := reference.ParseNormalizedNamed(rd)
+			if err != nil {
+				continue
+			}
+			if canonical, ok := ref.(reference.Canonical); ok && ref.Name() == named.Name() {
+				repoDigest = canonical.Digest().String()
+				break
+			}
+		}
+		if repoDigest == "" {
+			return lock, api.NewServiceError(name, api.ErrInvalidConfig, "image %q for service %q has no repository digest and can't be locked", image, name)
+		}
+		lock.Services[name] = imagesLockEntry{Image: image, Digest: repoDigest}
+	}
+	return lock, nil
+}
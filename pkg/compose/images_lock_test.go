@@ -0,0 +1,93 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	moby "github.com/docker/docker/api/types"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+func TestImagesLock(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+	path := filepath.Join(t.TempDir(), "images.lock")
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {Name: "app", Build: &types.BuildConfig{Context: "."}},
+			"db":  {Name: "db", Image: "postgres:16"},
+		},
+	}
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "postgres:16").Return(moby.ImageInspect{
+		ID:          "sha256:localid",
+		RepoDigests: []string{"mirror.example.com/postgres@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "postgres@sha256:dbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdb"},
+	}, nil, nil)
+
+	lock, err := tested.resolveImagesLock(context.Background(), project)
+	assert.NilError(t, err)
+	assert.NilError(t, writeImagesLock(path, lock))
+	lock, err = loadImagesLock(path)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, lock.Services, map[string]imagesLockEntry{
+		"app": {Image: "test-app", Build: true},
+		"db":  {Image: "postgres:16", Digest: "sha256:dbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdb"},
+	})
+
+	// locked images are pulled by digest, built ones are left unchanged
+	assert.NilError(t, lock.pin(project))
+	assert.Equal(t, project.Services["db"].Image, "postgres:16@sha256:dbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdbdb")
+	assert.Equal(t, project.Services["app"].Image, "")
+
+	project.Services["db"] = types.ServiceConfig{Name: "db", Image: "postgres:17"}
+	project.Services["app"] = types.ServiceConfig{Name: "app", Image: "test-app"}
+	project.Services["cache"] = types.ServiceConfig{Name: "cache", Image: "redis"}
+	err = lock.pin(project)
+	assert.Error(t, err, `images don't match lock file: service "app" uses test-app, locked to test-app (built), `+
+		`service "cache" is not locked, service "db" uses postgres:17, locked to postgres:16`)
+}
+
+func TestImagesLockWithoutRepoDigest(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"db": {Name: "db", Image: "postgres:16"},
+		},
+	}
+	// image has been tagged locally, it doesn't come from a registry
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "postgres:16").Return(moby.ImageInspect{ID: "sha256:localid"}, nil, nil)
+
+	_, err := tested.resolveImagesLock(context.Background(), project)
+	assert.ErrorContains(t, err, `image "postgres:16" for service "db" has no repository digest and can't be locked`)
+}
@@ -0,0 +1,113 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	moby "github.com/docker/docker/api/types"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+func (s *composeService) PruneImages(ctx context.Context, project *types.Project) error {
+	return progress.Run(ctx, func(ctx context.Context) error {
+		return s.pruneImages(ctx, project)
+	}, s.stdinfo())
+}
+
+func (s *composeService) pruneImages(ctx context.Context, project *types.Project) error {
+	images, err := s.unusedProjectImages(ctx, project)
+	if err != nil {
+		return err
+	}
+
+	w := progress.ContextWriter(ctx)
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, img := range images {
+		img := img
+		eg.Go(func() error {
+			return s.removeImage(ctx, img, w)
+		})
+	}
+	return eg.Wait()
+}
+
+// unusedProjectImages returns references to images built for the project which are neither used by any of the
+// project containers nor set as a project service image. Only the tags compose assigned by default to services
+// images are returned, so tags set by user are kept, and dangling images are returned by ID
+func (s *composeService) unusedProjectImages(ctx context.Context, project *types.Project) ([]string, error) {
+	projectName := strings.ToLower(project.Name)
+	containers, err := s.apiClient().ContainerList(ctx, containerType.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(projectFilter(projectName)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	for _, c := range containers {
+		used[c.ImageID] = true
+	}
+	referenced := map[string]bool{}
+	for _, service := range project.Services {
+		if ref := normalizedImageRef(api.GetImageNameOrDefault(service, project.Name)); ref != "" {
+			referenced[ref] = true
+		}
+	}
+
+	summaries, err := s.apiClient().ImageList(ctx, moby.ImageListOptions{
+		Filters: filters.NewArgs(projectFilter(projectName)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var images []string
+	for _, img := range summaries {
+		if used[img.ID] {
+			continue
+		}
+		if len(img.RepoTags) == 0 {
+			images = append(images, img.ID)
+			continue
+		}
+		var managed []string
+		inUse := false
+		for _, tag := range img.RepoTags {
+			ref := normalizedImageRef(tag)
+			if referenced[ref] {
+				inUse = true
+				break
+			}
+			if strings.HasPrefix(tag, projectName+api.Separator) {
+				managed = append(managed, tag)
+			}
+		}
+		if !inUse {
+			images = append(images, managed...)
+		}
+	}
+	sort.Strings(images)
+	return images, nil
+}
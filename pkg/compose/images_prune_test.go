@@ -0,0 +1,74 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	moby "github.com/docker/docker/api/types"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/errdefs"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+func TestPruneImages(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+	projectName := strings.ToLower(testProject)
+	project := &types.Project{
+		Name: projectName,
+		Services: types.Services{
+			"web": {Name: "web", Build: &types.BuildConfig{Context: "."}},
+			// api containers have been removed, but image is still the one service runs with
+			"api": {Name: "api", Build: &types.BuildConfig{Context: "."}},
+		},
+	}
+
+	apiClient.EXPECT().ContainerList(gomock.Any(), containerType.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(projectFilter(projectName)),
+	}).Return([]moby.Container{
+		{ID: "123", ImageID: "sha256:web"},
+	}, nil)
+	apiClient.EXPECT().ImageList(gomock.Any(), moby.ImageListOptions{
+		Filters: filters.NewArgs(projectFilter(projectName)),
+	}).Return([]image.Summary{
+		{ID: "sha256:web", RepoTags: []string{"testproject-web:latest"}},
+		{ID: "sha256:api", RepoTags: []string{"testproject-api:latest"}},
+		{ID: "sha256:old", RepoTags: []string{"testproject-worker:latest", "worker:1.0"}},
+		{ID: "sha256:dangling"},
+	}, nil)
+
+	apiClient.EXPECT().ImageRemove(gomock.Any(), "testproject-worker:latest", moby.ImageRemoveOptions{}).Return(nil, nil)
+	apiClient.EXPECT().ImageRemove(gomock.Any(), "sha256:dangling", moby.ImageRemoveOptions{}).
+		Return(nil, errdefs.Conflict(errors.New("image has dependent child images")))
+
+	err := tested.pruneImages(context.Background(), project)
+	assert.NilError(t, err)
+}
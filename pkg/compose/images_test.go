@@ -0,0 +1,62 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	moby "github.com/docker/docker/api/types"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestImages(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	web := testContainer("web", "123", false)
+	web.ImageID = "sha256:web"
+	apiClient.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return([]moby.Container{web}, nil)
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "sha256:web").Return(moby.ImageInspect{
+		ID:       "sha256:web",
+		RepoTags: []string{"registry.example.com/web:1.0"},
+		Size:     1024,
+		Created:  "2024-03-01T10:00:00.123456789Z",
+	}, nil, nil)
+
+	images, err := tested.Images(context.Background(), testProject, api.ImagesOptions{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, images, []api.ImageSummary{
+		{
+			ID:            "sha256:web",
+			ContainerName: "123",
+			Repository:    "registry.example.com/web",
+			Tag:           "1.0",
+			Size:          1024,
+			Created:       time.Date(2024, time.March, 1, 10, 0, 0, 123456789, time.UTC),
+		},
+	})
+}
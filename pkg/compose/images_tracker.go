@@ -0,0 +1,84 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/compose-spec/compose-go/v2/types"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+type imagesTrackerKey struct{}
+
+// imagesTracker records services which image has been pulled or built, so we can report the ones
+// which didn't complete when ensureImagesExists is interrupted
+type imagesTracker struct {
+	mux      sync.Mutex
+	resolved map[string]bool
+}
+
+func withImagesTracker(ctx context.Context) (context.Context, *imagesTracker) {
+	t := &imagesTracker{resolved: map[string]bool{}}
+	return context.WithValue(ctx, imagesTrackerKey{}, t), t
+}
+
+// imageResolved marks service image as available, if ctx is tracked
+func imageResolved(ctx context.Context, service string) {
+	t, ok := ctx.Value(imagesTrackerKey{}).(*imagesTracker)
+	if !ok {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.resolved[service] = true
+}
+
+// unresolved returns the services, sorted by name, which image has not been marked as resolved
+func (t *imagesTracker) unresolved(services []string) []string {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	var pending []string
+	for _, service := range services {
+		if !t.resolved[service] {
+			pending = append(pending, service)
+		}
+	}
+	sort.Strings(pending)
+	return pending
+}
+
+// imagesToResolve returns the services which image will be pulled or built by ensureImagesExists
+func imagesToResolve(project *types.Project, images map[string]string, build bool) []string {
+	var services []string
+	for name, service := range project.Services {
+		if mustPull(service, images) {
+			services = append(services, name)
+			continue
+		}
+		if build && service.Build != nil {
+			_, present := images[api.GetImageNameOrDefault(service, project.Name)]
+			if !present || service.PullPolicy == types.PullPolicyBuild {
+				services = append(services, name)
+			}
+		}
+	}
+	return services
+}
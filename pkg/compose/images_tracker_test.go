@@ -0,0 +1,69 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/config/configfile"
+	moby "github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+func TestEnsureImagesExistsTimeout(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	tested := composeService{
+		dockerCli: cli,
+	}
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app":   {Name: "app", Image: "registry.example.com/app:1.0"},
+			"db":    {Name: "db", Image: "registry.example.com/db:1.0"},
+			"cache": {Name: "cache", Image: "registry.example.com/cache:1.0"},
+		},
+	}
+	notFound := errdefs.NotFound(errors.New("no such image"))
+	api.EXPECT().ImageInspectWithRaw(gomock.Any(), "registry.example.com/cache:1.0").Return(moby.ImageInspect{ID: "sha256:cache"}, nil, nil)
+	api.EXPECT().ImageInspectWithRaw(gomock.Any(), "registry.example.com/app:1.0").Return(moby.ImageInspect{}, nil, notFound)
+	gomock.InOrder(
+		api.EXPECT().ImageInspectWithRaw(gomock.Any(), "registry.example.com/db:1.0").Return(moby.ImageInspect{}, nil, notFound),
+		api.EXPECT().ImageInspectWithRaw(gomock.Any(), "registry.example.com/db:1.0").Return(moby.ImageInspect{ID: "sha256:db"}, nil, nil),
+	)
+	api.EXPECT().ImagePull(gomock.Any(), "registry.example.com/db:1.0", gomock.Any()).Return(io.NopCloser(strings.NewReader("")), nil)
+	api.EXPECT().ImagePull(gomock.Any(), "registry.example.com/app:1.0", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ string, _ moby.ImagePullOptions) (io.ReadCloser, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+	err := tested.ensureImagesExists(context.Background(), project, ensureImagesOptions{QuietPull: true, Timeout: 100 * time.Millisecond})
+	assert.Check(t, errors.Is(err, context.DeadlineExceeded))
+	assert.ErrorContains(t, err, "timeout after 100ms waiting for images of service(s): app:")
+}
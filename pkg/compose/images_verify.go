@@ -0,0 +1,73 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// verifyImages runs image verifier on services images, so containers don't get created from untrusted ones
+func (s *composeService) verifyImages(ctx context.Context, project *types.Project) error {
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, service := range project.Services {
+		service := service
+		eg.Go(func() error {
+			image := api.GetImageNameOrDefault(service, project.Name)
+			inspect, _, err := s.apiClient().ImageInspectWithRaw(ctx, image)
+			if err != nil {
+				return err
+			}
+			digest, ok := repositoryDigest(image, inspect.RepoDigests)
+			if !ok {
+				if service.Build != nil {
+					// image has been built locally, there's nothing to verify it against
+					return nil
+				}
+				return api.NewServiceError(service.Name, api.ErrForbidden, "image %q for service %q has no repository digest and can't be verified", image, service.Name)
+			}
+			if err := s.verifier.Verify(ctx, service, digest); err != nil {
+				return api.NewServiceError(service.Name, api.ErrForbidden, "image %q for service %q failed verification: %w", digest, service.Name, err)
+			}
+			return nil
+		})
+	}
+	return eg.Wait()
+}
+
+// repositoryDigest selects, among image repository digests, the one from the repository image has been pulled from
+func repositoryDigest(image string, repoDigests []string) (string, bool) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", false
+	}
+	for _, repoDigest := range repoDigests {
+		ref, err := reference.ParseNormalizedNamed(repoDigest)
+		if err != nil {
+			continue
+		}
+		if _, ok := ref.(reference.Digested); ok && ref.Name() == named.Name() {
+			return ref.String(), true
+		}
+	}
+	return "", false
+}
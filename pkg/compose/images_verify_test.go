@@ -0,0 +1,105 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	moby "github.com/docker/docker/api/types"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+type fakeVerifier struct {
+	mux      sync.Mutex
+	verified map[string]string
+	reject   string
+}
+
+func (v *fakeVerifier) Verify(_ context.Context, service types.ServiceConfig, image string) error {
+	v.mux.Lock()
+	defer v.mux.Unlock()
+	if service.Name == v.reject {
+		return errors.New("signature mismatch")
+	}
+	if v.verified == nil {
+		v.verified = map[string]string{}
+	}
+	v.verified[service.Name] = image
+	return nil
+}
+
+const verifiedDigest = "sha256:4c0fdaa8b6341bfdeca5f18f7837462c80cff90527ee35ef185571e1c327beac"
+
+func TestVerifyImages(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	verifier := &fakeVerifier{}
+	tested := composeService{
+		dockerCli: cli,
+	}
+	tested.UseImageVerifier(verifier)
+
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"web": {Name: "web", Image: "nginx"},
+			"app": {Name: "app", Build: &types.BuildConfig{Context: "."}},
+		},
+	}
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "nginx").Return(moby.ImageInspect{
+		RepoDigests: []string{"mirror.example.com/nginx@" + verifiedDigest, "nginx@" + verifiedDigest},
+	}, nil, nil).AnyTimes()
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "test-app").Return(moby.ImageInspect{}, nil, nil).AnyTimes()
+
+	assert.NilError(t, tested.verifyImages(context.Background(), project))
+	assert.DeepEqual(t, verifier.verified, map[string]string{
+		"web": "docker.io/library/nginx@" + verifiedDigest,
+	})
+
+	verifier.reject = "web"
+	err := tested.verifyImages(context.Background(), project)
+	assert.ErrorContains(t, err, `image "docker.io/library/nginx@`+verifiedDigest+`" for service "web" failed verification: signature mismatch`)
+}
+
+func TestVerifyImagesWithoutRepositoryDigest(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+	tested.UseImageVerifier(&fakeVerifier{})
+
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"web": {Name: "web", Image: "nginx"},
+		},
+	}
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "nginx").Return(moby.ImageInspect{}, nil, nil)
+
+	err := tested.verifyImages(context.Background(), project)
+	assert.ErrorContains(t, err, `image "nginx" for service "web" has no repository digest and can't be verified`)
+}
@@ -45,19 +45,18 @@ func (s *composeService) kill(ctx context.Context, projectName string, options a
 		return err
 	}
 
-	project := options.Project
-	if project == nil {
-		project, err = s.getProjectWithResources(ctx, containers, projectName)
-		if err != nil {
-			return err
-		}
-	}
-
 	if !options.RemoveOrphans {
+		project := options.Project
+		if project == nil {
+			project, err = s.getProjectWithResources(ctx, containers, projectName)
+			if err != nil {
+				return err
+			}
+		}
 		containers = containers.filter(isService(project.ServiceNames()...))
 	}
 	if len(containers) == 0 {
-		fmt.Fprintf(s.stdinfo(), "no container to kill")
+		fmt.Fprintln(s.stdinfo(), "no container to kill")
 		return nil
 	}
 
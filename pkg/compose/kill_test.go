@@ -102,6 +102,30 @@ func TestKillSignal(t *testing.T) {
 	assert.NilError(t, err)
 }
 
+func TestKillRemoveOrphans(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	name := strings.ToLower(testProject)
+
+	ctx := context.Background()
+	api.EXPECT().ContainerList(ctx, containerType.ListOptions{
+		Filters: filters.NewArgs(projectFilter(name), hasConfigHashLabel()),
+	}).Return(
+		[]moby.Container{testContainer("service1", "123", false), testContainer("orphan", "456", false)}, nil)
+	// project resources are not needed to select containers
+	api.EXPECT().ContainerKill(anyCancellableContext(), "123", "SIGKILL").Return(nil)
+	api.EXPECT().ContainerKill(anyCancellableContext(), "456", "SIGKILL").Return(nil)
+
+	err := tested.kill(ctx, name, compose.KillOptions{RemoveOrphans: true, Signal: "SIGKILL"})
+	assert.NilError(t, err)
+}
+
 func testContainer(service string, id string, oneOff bool) moby.Container {
 	// canonical docker names in the API start with a leading slash, some
 	// parts of Compose code will attempt to strip this off, so make sure
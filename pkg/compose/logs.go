@@ -19,6 +19,7 @@ package compose
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"time"
 
@@ -150,18 +151,24 @@ func (s *composeService) logContainers(ctx context.Context, consumer api.LogCons
 		Timestamps: options.Timestamps,
 	})
 	if err != nil {
+		if errdefs.IsNotImplemented(err) && cnt.HostConfig != nil {
+			return errdefs.NotImplemented(fmt.Errorf("logging driver %q: %w", cnt.HostConfig.LogConfig.Type, err))
+		}
 		return err
 	}
 	defer r.Close() //nolint:errcheck
 
 	name := getContainerNameWithoutProject(c)
-	w := utils.GetWriter(func(line string) {
+	wOut := utils.GetWriter(func(line string) {
 		consumer.Log(name, line)
 	})
+	wErr := utils.GetWriter(func(line string) {
+		consumer.Err(name, line)
+	})
 	if cnt.Config.Tty {
-		_, err = io.Copy(w, r)
+		_, err = io.Copy(wOut, r)
 	} else {
-		_, err = stdcopy.StdCopy(w, w, r)
+		_, err = stdcopy.StdCopy(wOut, wErr, r)
 	}
 	return err
 }
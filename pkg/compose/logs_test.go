@@ -18,6 +18,7 @@ package compose
 
 import (
 	"context"
+	"errors"
 	"io"
 	"strings"
 	"sync"
@@ -27,6 +28,7 @@ import (
 	moby "github.com/docker/docker/api/types"
 	containerType "github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -94,9 +96,14 @@ func TestComposeService_Logs_Demux(t *testing.T) {
 
 	require.Equal(
 		t,
-		[]string{"hello stdout", "hello stderr"},
+		[]string{"hello stdout"},
 		consumer.LogsForContainer("c"),
 	)
+	require.Equal(
+		t,
+		[]string{"hello stderr"},
+		consumer.ErrsForContainer("c"),
+	)
 }
 
 // TestComposeService_Logs_ServiceFiltering ensures that we do not include
@@ -170,10 +177,41 @@ func TestComposeService_Logs_ServiceFiltering(t *testing.T) {
 	require.Equal(t, []string{"hello c4"}, consumer.LogsForContainer("c4"))
 }
 
+func TestComposeService_Logs_UnsupportedDriver(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	ctx := context.Background()
+	c := testContainer("service", "c", false)
+	api.EXPECT().ContainerInspect(gomock.Any(), "c").Return(moby.ContainerJSON{
+		ContainerJSONBase: &moby.ContainerJSONBase{
+			ID: "c",
+			HostConfig: &containerType.HostConfig{
+				LogConfig: containerType.LogConfig{Type: "syslog"},
+			},
+		},
+		Config: &containerType.Config{},
+	}, nil)
+	api.EXPECT().ContainerLogs(gomock.Any(), "c", gomock.Any()).
+		Return(nil, errdefs.NotImplemented(errors.New("configured logging driver does not support reading")))
+
+	err := tested.logContainers(ctx, &testLogConsumer{}, c, compose.LogOptions{})
+	require.Error(t, err)
+	assert.True(t, errdefs.IsNotImplemented(err))
+	assert.Equal(t, `logging driver "syslog": configured logging driver does not support reading`, err.Error())
+}
+
 type testLogConsumer struct {
 	mu sync.Mutex
 	// logs is keyed by container ID; values are log lines
 	logs map[string][]string
+	// errs is keyed by container ID; values are stderr log lines
+	errs map[string][]string
 }
 
 func (l *testLogConsumer) Log(containerName, message string) {
@@ -186,7 +224,12 @@ func (l *testLogConsumer) Log(containerName, message string) {
 }
 
 func (l *testLogConsumer) Err(containerName, message string) {
-	l.Log(containerName, message)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.errs == nil {
+		l.errs = make(map[string][]string)
+	}
+	l.errs[containerName] = append(l.errs[containerName], message)
 }
 
 func (l *testLogConsumer) Status(containerName, msg string) {}
@@ -198,3 +241,9 @@ func (l *testLogConsumer) LogsForContainer(containerName string) []string {
 	defer l.mu.Unlock()
 	return l.logs[containerName]
 }
+
+func (l *testLogConsumer) ErrsForContainer(containerName string) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.errs[containerName]
+}
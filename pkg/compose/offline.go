@@ -0,0 +1,89 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"fmt"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/buildx/util/buildflags"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func (s *composeService) OfflineMode(offline bool) {
+	s.offline = offline
+}
+
+// offlineError reports operation can't run as it requires network access
+func offlineError(operation string) error {
+	return fmt.Errorf("%s requires network access, which is disabled in offline mode", operation)
+}
+
+// checkOfflineBuild rejects build options which require network access
+func checkOfflineBuild(options api.BuildOptions) error {
+	switch {
+	case options.Push:
+		return offlineError("pushing built images")
+	case options.CheckBaseImages:
+		return offlineError("checking base images")
+	case options.Pull:
+		return offlineError("pulling base images")
+	}
+	return nil
+}
+
+// offlineBuildConfig returns a copy of build config which doesn't pull base images nor import or export
+// remote build cache, so only local images and cache are used
+func offlineBuildConfig(build types.BuildConfig) (types.BuildConfig, error) {
+	build.Pull = false
+	var err error
+	if build.CacheFrom, err = localCacheEntries(build.CacheFrom); err != nil {
+		return build, err
+	}
+	build.CacheTo, err = localCacheEntries(build.CacheTo)
+	return build, err
+}
+
+func localCacheEntries(entries types.StringList) (types.StringList, error) {
+	var local types.StringList
+	for _, entry := range entries {
+		parsed, err := buildflags.ParseCacheEntry([]string{entry})
+		if err != nil {
+			return nil, err
+		}
+		if len(parsed) > 0 && parsed[0].Type == "local" {
+			local = append(local, entry)
+		}
+	}
+	return local, nil
+}
+
+// checkOfflineImages makes sure images which would otherwise be pulled are available locally, or can be built
+func checkOfflineImages(project *types.Project, needPull []types.ServiceConfig, images map[string]string) error {
+	for _, service := range needPull {
+		if _, ok := images[service.Image]; ok {
+			// pull_policy requested a fresh image, but local one will do
+			continue
+		}
+		if isServiceImageToBuild(service, project.Services) {
+			continue
+		}
+		return api.NewServiceError(service.Name, api.ErrNotFound, "image %q for service %q is not available locally, and can't be pulled in offline mode", service.Image, service.Name)
+	}
+	return nil
+}
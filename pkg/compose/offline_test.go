@@ -0,0 +1,72 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestOfflineBuildConfig(t *testing.T) {
+	build, err := offlineBuildConfig(types.BuildConfig{
+		Context:   ".",
+		Pull:      true,
+		CacheFrom: types.StringList{"type=registry,ref=registry.example.com/app:cache", "type=local,src=/tmp/cache"},
+		CacheTo:   types.StringList{"type=gha"},
+	})
+	assert.NilError(t, err)
+	assert.Check(t, !build.Pull)
+	assert.DeepEqual(t, build.CacheFrom, types.StringList{"type=local,src=/tmp/cache"})
+	assert.Equal(t, len(build.CacheTo), 0)
+
+	assert.ErrorContains(t, checkOfflineBuild(api.BuildOptions{Push: true}), "pushing built images requires network access")
+	assert.NilError(t, checkOfflineBuild(api.BuildOptions{}))
+}
+
+func TestOfflinePullRequiredImages(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	_, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+		offline:   true,
+	}
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"db":  {Name: "db", Image: "postgres", PullPolicy: types.PullPolicyAlways},
+			"app": {Name: "app", Image: "registry.example.com/app", Build: &types.BuildConfig{Context: "."}},
+		},
+	}
+	// no engine call is expected, as nothing gets pulled
+	images := map[string]string{"postgres": "sha256:postgres"}
+	assert.NilError(t, tested.pullRequiredImages(context.Background(), project, images, true, 0, false))
+
+	delete(images, "postgres")
+	err := tested.pullRequiredImages(context.Background(), project, images, true, 0, false)
+	assert.ErrorContains(t, err, `image "postgres" for service "db" is not available locally, and can't be pulled in offline mode`)
+
+	err = tested.Pull(context.Background(), project, api.PullOptions{})
+	assert.ErrorContains(t, err, "pulling images requires network access, which is disabled in offline mode")
+}
@@ -42,6 +42,8 @@ func (s *composeService) pause(ctx context.Context, projectName string, options
 	if options.Project != nil {
 		containers = containers.filter(isService(options.Project.ServiceNames()...))
 	}
+	// already paused containers are left untouched
+	containers = containers.filter(isRunning())
 
 	w := progress.ContextWriter(ctx)
 	eg, ctx := errgroup.WithContext(ctx)
@@ -74,12 +76,13 @@ func (s *composeService) unPause(ctx context.Context, projectName string, option
 	if options.Project != nil {
 		containers = containers.filter(isService(options.Project.ServiceNames()...))
 	}
+	containers = containers.filter(isPaused())
 
 	w := progress.ContextWriter(ctx)
 	eg, ctx := errgroup.WithContext(ctx)
 	containers.forEach(func(container moby.Container) {
 		eg.Go(func() error {
-			err = s.apiClient().ContainerUnpause(ctx, container.ID)
+			err := s.apiClient().ContainerUnpause(ctx, container.ID)
 			if err == nil {
 				eventName := getContainerProgressName(container)
 				w.Event(progress.NewEvent(eventName, progress.Done, "Unpaused"))
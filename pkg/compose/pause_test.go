@@ -0,0 +1,52 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	moby "github.com/docker/docker/api/types"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestPauseUnpause(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+	name := strings.ToLower(testProject)
+
+	running := testContainer("web", "123", false)
+	running.State = ContainerRunning
+	paused := testContainer("web", "456", false)
+	paused.State = ContainerPaused
+	apiClient.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return([]moby.Container{running, paused}, nil).Times(2)
+
+	apiClient.EXPECT().ContainerPause(gomock.Any(), "123").Return(nil)
+	assert.NilError(t, tested.pause(context.Background(), name, api.PauseOptions{}))
+
+	apiClient.EXPECT().ContainerUnpause(gomock.Any(), "456").Return(nil)
+	assert.NilError(t, tested.unPause(context.Background(), name, api.PauseOptions{}))
+}
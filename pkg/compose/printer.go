@@ -81,8 +81,9 @@ func (p *printer) HandleEvent(event api.ContainerEvent) {
 //nolint:gocyclo
 func (p *printer) Run(cascadeStop bool, exitCodeFrom string, stopFn func() error) (int, error) {
 	var (
-		aborting bool
-		exitCode int
+		aborting    bool
+		exitCode    int
+		exitCodeSet bool
 	)
 	defer p.Stop()
 
@@ -127,8 +128,10 @@ func (p *printer) Run(cascadeStop bool, exitCodeFrom string, stopFn func() error
 						if exitCodeFrom == "" {
 							exitCodeFrom = event.Service
 						}
-						if exitCodeFrom == event.Service {
+						// only the first container to exit reports the exit code, others being stopped as a consequence
+						if exitCodeFrom == event.Service && !exitCodeSet {
 							exitCode = event.ExitCode
+							exitCodeSet = true
 						}
 					}
 				}
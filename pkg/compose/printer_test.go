@@ -0,0 +1,67 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestPrinterCascadeStopExitCode(t *testing.T) {
+	tests := []struct {
+		name         string
+		exitCodeFrom string
+		expected     int
+	}{
+		{name: "first container to exit", expected: 0},
+		{name: "selected service", exitCodeFrom: "db", expected: 137},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newLogPrinter(&testLogConsumer{})
+			stopped := 0
+			type result struct {
+				code int
+				err  error
+			}
+			done := make(chan result)
+			go func() {
+				code, err := p.Run(true, tt.exitCodeFrom, func() error {
+					stopped++
+					return nil
+				})
+				done <- result{code, err}
+			}()
+
+			p.HandleEvent(api.ContainerEvent{Type: api.ContainerEventAttach, ID: "web1", Container: "web-1", Service: "web"})
+			p.HandleEvent(api.ContainerEvent{Type: api.ContainerEventAttach, ID: "web2", Container: "web-2", Service: "web"})
+			p.HandleEvent(api.ContainerEvent{Type: api.ContainerEventAttach, ID: "db1", Container: "db-1", Service: "db"})
+			p.HandleEvent(api.ContainerEvent{Type: api.ContainerEventExit, ID: "web1", Container: "web-1", Service: "web", ExitCode: 0})
+			// other containers are stopped as a consequence
+			p.HandleEvent(api.ContainerEvent{Type: api.ContainerEventExit, ID: "web2", Container: "web-2", Service: "web", ExitCode: 143})
+			p.HandleEvent(api.ContainerEvent{Type: api.ContainerEventExit, ID: "db1", Container: "db-1", Service: "db", ExitCode: 137})
+
+			r := <-done
+			assert.NilError(t, r.err)
+			assert.Equal(t, r.code, tt.expected)
+			assert.Equal(t, stopped, 1)
+		})
+	}
+}
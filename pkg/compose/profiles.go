@@ -0,0 +1,30 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// withProfiles returns a copy of project with only services matching profiles enabled,
+// or project unchanged when no profile is set
+func withProfiles(project *types.Project, profiles []string) (*types.Project, error) {
+	if len(profiles) == 0 {
+		return project, nil
+	}
+	return project.WithProfiles(profiles)
+}
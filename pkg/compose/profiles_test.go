@@ -0,0 +1,49 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestWithProfiles(t *testing.T) {
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"web":   {Name: "web"},
+			"debug": {Name: "debug", Profiles: []string{"debug"}},
+			"tests": {Name: "tests", Profiles: []string{"test"}},
+		},
+	}
+
+	unchanged, err := withProfiles(project, nil)
+	assert.NilError(t, err)
+	assert.Check(t, unchanged == project)
+
+	withDebug, err := withProfiles(project, []string{"debug"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, withDebug.ServiceNames(), []string{"debug", "web"})
+	assert.DeepEqual(t, withDebug.DisabledServiceNames(), []string{"tests"})
+	assert.Equal(t, len(project.Services), 3)
+
+	all, err := withProfiles(project, []string{"*"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(all.Services), 3)
+}
@@ -19,6 +19,7 @@ package compose
 import (
 	"context"
 	"sort"
+	"strconv"
 	"strings"
 
 	"golang.org/x/sync/errgroup"
@@ -93,6 +94,9 @@ func (s *composeService) Ps(ctx context.Context, projectName string, options api
 				mounts = append(mounts, name)
 			}
 
+			// number is missing from containers created by older versions
+			number, _ := strconv.Atoi(container.Labels[api.ContainerNumberLabel])
+
 			var networks []string
 			if container.NetworkSettings != nil {
 				for k := range container.NetworkSettings.Networks {
@@ -107,6 +111,7 @@ func (s *composeService) Ps(ctx context.Context, projectName string, options api
 				Image:        container.Image,
 				Project:      container.Labels[api.ProjectLabel],
 				Service:      container.Labels[api.ServiceLabel],
+				Number:       number,
 				Command:      container.Command,
 				State:        container.State,
 				Status:       container.Status,
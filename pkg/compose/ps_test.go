@@ -44,6 +44,7 @@ func TestPs(t *testing.T) {
 	args.Add("label", "com.docker.compose.oneoff=False")
 	listOpts := containerType.ListOptions{Filters: args, All: false}
 	c1, inspect1 := containerDetails("service1", "123", "running", "healthy", 0)
+	c1.Labels[compose.ContainerNumberLabel] = "1"
 	c2, inspect2 := containerDetails("service1", "456", "running", "", 0)
 	c2.Ports = []moby.Port{{PublicPort: 80, PrivatePort: 90, IP: "localhost"}}
 	c3, inspect3 := containerDetails("service2", "789", "exited", "", 130)
@@ -56,12 +57,13 @@ func TestPs(t *testing.T) {
 
 	expected := []compose.ContainerSummary{
 		{ID: "123", Name: "123", Names: []string{"/123"}, Image: "foo", Project: strings.ToLower(testProject), Service: "service1",
-			State: "running", Health: "healthy", Publishers: nil,
+			Number: 1, State: "running", Health: "healthy", Publishers: nil,
 			Labels: map[string]string{
-				compose.ProjectLabel:     strings.ToLower(testProject),
-				compose.ConfigFilesLabel: "/src/pkg/compose/testdata/compose.yaml",
-				compose.WorkingDirLabel:  "/src/pkg/compose/testdata",
-				compose.ServiceLabel:     "service1",
+				compose.ProjectLabel:         strings.ToLower(testProject),
+				compose.ConfigFilesLabel:     "/src/pkg/compose/testdata/compose.yaml",
+				compose.WorkingDirLabel:      "/src/pkg/compose/testdata",
+				compose.ServiceLabel:         "service1",
+				compose.ContainerNumberLabel: "1",
 			},
 		},
 		{ID: "456", Name: "456", Names: []string{"/456"}, Image: "foo", Project: strings.ToLower(testProject), Service: "service1",
@@ -29,6 +29,9 @@ import (
 )
 
 func (s *composeService) Publish(ctx context.Context, project *types.Project, repository string, options api.PublishOptions) error {
+	if s.offline {
+		return offlineError("publishing project")
+	}
 	return progress.RunWithTitle(ctx, func(ctx context.Context) error {
 		return s.publish(ctx, project, repository, options)
 	}, s.stdinfo(), "Publishing")
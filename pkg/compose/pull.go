@@ -23,18 +23,24 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/compose-spec/compose-go/v2/types"
+	cerrdefs "github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/platforms"
 	"github.com/distribution/reference"
 	"github.com/docker/buildx/driver"
 	"github.com/docker/cli/cli/config/configfile"
 	moby "github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/registry"
 	"github.com/hashicorp/go-multierror"
 	"github.com/opencontainers/go-digest"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/docker/compose/v2/pkg/api"
@@ -42,6 +48,13 @@ import (
 )
 
 func (s *composeService) Pull(ctx context.Context, project *types.Project, options api.PullOptions) error {
+	if s.offline {
+		return offlineError("pulling images")
+	}
+	project, err := withProfiles(project, options.Profiles)
+	if err != nil {
+		return err
+	}
 	if options.Quiet {
 		return s.pull(ctx, project, options)
 	}
@@ -51,7 +64,7 @@ func (s *composeService) Pull(ctx context.Context, project *types.Project, optio
 }
 
 func (s *composeService) pull(ctx context.Context, project *types.Project, opts api.PullOptions) error { //nolint:gocyclo
-	images, err := s.getLocalImagesDigests(ctx, project)
+	images, err := s.getLocalImagesDigests(ctx, project, opts.Retries)
 	if err != nil {
 		return err
 	}
@@ -62,6 +75,7 @@ func (s *composeService) pull(ctx context.Context, project *types.Project, opts
 
 	var (
 		mustBuild         []string
+		mustBuildMu       sync.Mutex
 		pullErrors        = make([]error, len(project.Services))
 		imagesBeingPulled = map[string]string{}
 	)
@@ -118,11 +132,13 @@ func (s *composeService) pull(ctx context.Context, project *types.Project, opts
 
 		idx, name, service := i, name, service
 		eg.Go(func() error {
-			_, err := s.pullServiceImage(ctx, service, s.configFile(), w, false, project.Environment["DOCKER_DEFAULT_PLATFORM"])
+			_, err := s.pullServiceImageWithRetries(ctx, service, w, false, project.Environment["DOCKER_DEFAULT_PLATFORM"], opts.Retries, opts.RetryNotFound)
 			if err != nil {
 				pullErrors[idx] = err
 				if service.Build != nil {
+					mustBuildMu.Lock()
 					mustBuild = append(mustBuild, service.Name)
+					mustBuildMu.Unlock()
 				}
 				if !opts.IgnoreFailures && service.Build == nil {
 					if s.dryRun {
@@ -144,6 +160,7 @@ func (s *composeService) pull(ctx context.Context, project *types.Project, opts
 	err = eg.Wait()
 
 	if len(mustBuild) > 0 {
+		sort.Strings(mustBuild)
 		w.TailMsgf("WARNING: Some service image(s) must be built from source by running:\n    docker compose build %s", strings.Join(mustBuild, " "))
 	}
 
@@ -171,6 +188,13 @@ func imageAlreadyPresent(serviceImage string, localImages map[string]string) boo
 
 func (s *composeService) pullServiceImage(ctx context.Context, service types.ServiceConfig,
 	configFile driver.Auth, w progress.Writer, quietPull bool, defaultPlatform string) (string, error) {
+	if s.builder != nil {
+		platform := service.Platform
+		if platform == "" {
+			platform = defaultPlatform
+		}
+		return s.pullWithBuilder(ctx, service, w, platform)
+	}
 	w.Event(progress.Event{
 		ID:     service.Name,
 		Status: progress.Working,
@@ -191,10 +215,22 @@ func (s *composeService) pullServiceImage(ctx context.Context, service types.Ser
 		platform = defaultPlatform
 	}
 
+	// engine doesn't tell which platforms are available when none matches, look for them to report a meaningful error
+	withPlatformError := func(err error) error {
+		if platform == "" || !errdefs.IsNotFound(err) {
+			return err
+		}
+		if mismatch := s.checkImagePlatform(ctx, service, ref, encodedAuth, platform); mismatch != nil {
+			return mismatch
+		}
+		return err
+	}
+
 	stream, err := s.apiClient().ImagePull(ctx, service.Image, moby.ImagePullOptions{
 		RegistryAuth: encodedAuth,
 		Platform:     platform,
 	})
+	err = withPlatformError(err)
 
 	// check if has error and the service has a build section
 	// then the status should be warning instead of error
@@ -226,7 +262,7 @@ func (s *composeService) pullServiceImage(ctx context.Context, service types.Ser
 			return "", WrapCategorisedComposeError(err, PullFailure)
 		}
 		if jm.Error != nil {
-			return "", WrapCategorisedComposeError(errors.New(jm.Error.Message), PullFailure)
+			return "", WrapCategorisedComposeError(withPlatformError(errdefs.FromStatusCode(errors.New(jm.Error.Message), jm.Error.Code)), PullFailure)
 		}
 		if !quietPull {
 			toPullProgressEvent(service.Name, jm, w)
@@ -245,6 +281,59 @@ func (s *composeService) pullServiceImage(ctx context.Context, service types.Ser
 	return inspected.ID, nil
 }
 
+// checkImagePlatform reports image is not available for the platform required by service. It returns nil when
+// image is available for this platform, or available platforms can't be inspected
+func (s *composeService) checkImagePlatform(ctx context.Context, service types.ServiceConfig, ref reference.Named, encodedAuth string, platform string) error {
+	required, err := platforms.Parse(platform)
+	if err != nil {
+		return err
+	}
+	inspect, err := s.apiClient().DistributionInspect(ctx, ref.String(), encodedAuth)
+	if err != nil {
+		// registry might not support manifest inspection, let pull error be reported
+		logrus.Debugf("failed to inspect manifest for image %s: %v", ref, err)
+		return nil
+	}
+	if len(inspect.Platforms) == 0 {
+		return nil
+	}
+	matcher := platforms.Only(required)
+	var available []string
+	for _, p := range inspect.Platforms {
+		if matcher.Match(p) {
+			return nil
+		}
+		available = append(available, platforms.Format(p))
+	}
+	return api.NewServiceError(service.Name, api.ErrInvalidConfig, "service %q requires platform %s, but image %q is only available for %s",
+		service.Name, platforms.Format(required), service.Image, strings.Join(available, ", "))
+}
+
+// notFoundRetries is the minimum number of retries when pulling images reported as not found is retried
+var notFoundRetries = 4
+
+// pullServiceImageWithRetries pulls service image, retrying after transient failures. When retryNotFound is set
+// images reported as not found are retried too, as registries might not immediately expose an image which has
+// just been pushed
+func (s *composeService) pullServiceImageWithRetries(ctx context.Context, service types.ServiceConfig, w progress.Writer,
+	quietPull bool, defaultPlatform string, retries int, retryNotFound bool) (string, error) {
+	retryable := isTransientError
+	if retryNotFound {
+		retries = max(retries, notFoundRetries)
+		retryable = func(err error) bool {
+			return isTransientError(err) || isImageNotFound(err)
+		}
+	}
+	return withRetries(ctx, retries, retryable, func() (string, error) {
+		return s.pullServiceImage(ctx, service, s.configFile(), w, quietPull, defaultPlatform)
+	}, retryEvent(w, service.Name, retries))
+}
+
+// isImageNotFound checks if err reports a missing image, either by engine or by a containerd based builder
+func isImageNotFound(err error) bool {
+	return errdefs.IsNotFound(err) || cerrdefs.IsNotFound(err) || api.IsNotFoundError(err)
+}
+
 // ImageDigestResolver creates a func able to resolve image digest from a docker ref,
 func ImageDigestResolver(ctx context.Context, file *configfile.ConfigFile, apiClient client.APIClient) func(named reference.Named) (digest.Digest, error) {
 	return func(named reference.Named) (digest.Digest, error) {
@@ -279,27 +368,26 @@ func encodedAuth(ref reference.Named, configFile driver.Auth) (string, error) {
 	return base64.URLEncoding.EncodeToString(buf), nil
 }
 
-func (s *composeService) pullRequiredImages(ctx context.Context, project *types.Project, images map[string]string, quietPull bool) error {
+func (s *composeService) pullRequiredImages(ctx context.Context, project *types.Project, images map[string]string, quietPull bool, retries int, retryNotFound bool) error {
 	var needPull []types.ServiceConfig
+	// services sharing the same image and platform only pull it once
+	sharing := map[string][]string{}
 	for _, service := range project.Services {
-		if service.Image == "" {
+		if !mustPull(service, images) {
 			continue
 		}
-		switch service.PullPolicy {
-		case "", types.PullPolicyMissing, types.PullPolicyIfNotPresent:
-			if _, ok := images[service.Image]; ok {
-				continue
-			}
-		case types.PullPolicyNever, types.PullPolicyBuild:
-			continue
-		case types.PullPolicyAlways:
-			// force pull
+		key := pullKey(service)
+		if _, ok := sharing[key]; !ok {
+			needPull = append(needPull, service)
 		}
-		needPull = append(needPull, service)
+		sharing[key] = append(sharing[key], service.Name)
 	}
 	if len(needPull) == 0 {
 		return nil
 	}
+	if s.offline {
+		return checkOfflineImages(project, needPull, images)
+	}
 
 	return progress.Run(ctx, func(ctx context.Context) error {
 		w := progress.ContextWriter(ctx)
@@ -309,8 +397,13 @@ func (s *composeService) pullRequiredImages(ctx context.Context, project *types.
 		for i, service := range needPull {
 			i, service := i, service
 			eg.Go(func() error {
-				id, err := s.pullServiceImage(ctx, service, s.configFile(), w, quietPull, project.Environment["DOCKER_DEFAULT_PLATFORM"])
+				id, err := s.pullServiceImageWithRetries(ctx, service, w, quietPull, project.Environment["DOCKER_DEFAULT_PLATFORM"], retries, retryNotFound)
 				pulledImages[i] = id
+				if err == nil {
+					for _, name := range sharing[pullKey(service)] {
+						imageResolved(ctx, name)
+					}
+				}
 				if err != nil && isServiceImageToBuild(service, project.Services) {
 					// image can be built, so we can ignore pull failure
 					return nil
@@ -328,6 +421,41 @@ func (s *composeService) pullRequiredImages(ctx context.Context, project *types.
 	}, s.stdinfo())
 }
 
+func pullKey(service types.ServiceConfig) string {
+	return service.Image + "\x00" + service.Platform
+}
+
+// mustPull checks if service image has to be pulled, according to pull policy and images available locally
+func mustPull(service types.ServiceConfig, images map[string]string) bool {
+	if service.Image == "" {
+		return false
+	}
+	_, present := images[service.Image]
+	if present && isDigestPinned(service.Image) {
+		// engine only resolves a digest reference when a local RepoDigest matches, and as content
+		// addressed by digest is immutable there's no need to check registry, even with policy `always`
+		return false
+	}
+	switch service.PullPolicy {
+	case "", types.PullPolicyMissing, types.PullPolicyIfNotPresent:
+		return !present
+	case types.PullPolicyNever, types.PullPolicyBuild:
+		return false
+	default:
+		// types.PullPolicyAlways
+		return true
+	}
+}
+
+func isDigestPinned(image string) bool {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return false
+	}
+	_, ok := named.(reference.Digested)
+	return ok
+}
+
 func isServiceImageToBuild(service types.ServiceConfig, services types.Services) bool {
 	if service.Build != nil {
 		return true
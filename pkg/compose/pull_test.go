@@ -0,0 +1,323 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli/config/configfile"
+	clitypes "github.com/docker/cli/cli/config/types"
+	moby "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/errdefs"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+func TestMustPull(t *testing.T) {
+	const pinned = "alpine@sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b"
+	images := map[string]string{
+		pinned:           "sha256:05455a08881ea9cf0e752bc48e61bbd71a34c029bb13df01e40e3e70e0d007bd",
+		"nginx:1.25":     "sha256:a8758716bb6aa4d90071160d27028fe4eaee7ce8166221a97d30440c8eac2be6",
+		"busybox:latest": "sha256:3f57d9401f8d42f986df300f0c69192fc41da28ccc8d797829467780db3dd741",
+	}
+
+	tests := []struct {
+		name     string
+		service  types.ServiceConfig
+		expected bool
+	}{
+		{
+			name:     "present digest-pinned image under always policy",
+			service:  types.ServiceConfig{Image: pinned, PullPolicy: types.PullPolicyAlways},
+			expected: false,
+		},
+		{
+			name:     "missing digest-pinned image",
+			service:  types.ServiceConfig{Image: "redis@sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b"},
+			expected: true,
+		},
+		{
+			name:     "present tagged image under always policy",
+			service:  types.ServiceConfig{Image: "busybox:latest", PullPolicy: types.PullPolicyAlways},
+			expected: true,
+		},
+		{
+			name:     "present tagged image under missing policy",
+			service:  types.ServiceConfig{Image: "nginx:1.25", PullPolicy: types.PullPolicyMissing},
+			expected: false,
+		},
+		{
+			name:     "missing image under never policy",
+			service:  types.ServiceConfig{Image: "postgres:16", PullPolicy: types.PullPolicyNever},
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, mustPull(tt.service, images), tt.expected)
+		})
+	}
+}
+
+func TestPullServiceImageRetryNotFound(t *testing.T) {
+	defer func(delay time.Duration) { transientRetryDelay = delay }(transientRetryDelay)
+	transientRetryDelay = time.Millisecond
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	tested := composeService{
+		dockerCli: cli,
+	}
+	ctx := context.Background()
+	service := types.ServiceConfig{Name: "app", Image: "registry.example.com/base:1.0"}
+	notFound := errdefs.NotFound(errors.New("manifest unknown"))
+	notFoundStream := `{"errorDetail":{"code":404,"message":"manifest unknown"},"error":"manifest unknown"}`
+
+	gomock.InOrder(
+		api.EXPECT().ImagePull(gomock.Any(), service.Image, gomock.Any()).Return(nil, notFound),
+		api.EXPECT().ImagePull(gomock.Any(), service.Image, gomock.Any()).Return(io.NopCloser(strings.NewReader(notFoundStream)), nil),
+		api.EXPECT().ImagePull(gomock.Any(), service.Image, gomock.Any()).Return(io.NopCloser(strings.NewReader("")), nil),
+	)
+	api.EXPECT().ImageInspectWithRaw(gomock.Any(), service.Image).Return(moby.ImageInspect{ID: "sha256:base"}, nil, nil)
+
+	id, err := tested.pullServiceImageWithRetries(ctx, service, progress.ContextWriter(ctx), false, "", 0, true)
+	assert.NilError(t, err)
+	assert.Equal(t, id, "sha256:base")
+
+	// without opt-in, not found fails immediately
+	api.EXPECT().ImagePull(gomock.Any(), service.Image, gomock.Any()).Return(nil, notFound).Times(1)
+	_, err = tested.pullServiceImageWithRetries(ctx, service, progress.ContextWriter(ctx), false, "", 3, false)
+	assert.ErrorContains(t, err, "manifest unknown")
+
+	// errors which only mention a missing resource are not retried
+	api.EXPECT().ImagePull(gomock.Any(), service.Image, gomock.Any()).Return(nil, errors.New("network not found")).Times(1)
+	_, err = tested.pullServiceImageWithRetries(ctx, service, progress.ContextWriter(ctx), false, "", 0, true)
+	assert.ErrorContains(t, err, "network not found")
+}
+
+func TestPullServiceImagePlatformMismatch(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	tested := composeService{
+		dockerCli: cli,
+	}
+	ctx := context.Background()
+	service := types.ServiceConfig{Name: "app", Image: "registry.example.com/app:1.0", Platform: "linux/arm64"}
+	noMatch := errdefs.NotFound(errors.New("no matching manifest for linux/arm64 in the manifest list entries"))
+
+	api.EXPECT().ImagePull(gomock.Any(), service.Image, gomock.Any()).Return(nil, noMatch)
+	api.EXPECT().DistributionInspect(gomock.Any(), service.Image, gomock.Any()).Return(registry.DistributionInspect{
+		Platforms: []specs.Platform{{OS: "linux", Architecture: "amd64"}},
+	}, nil)
+	_, err := tested.pullServiceImage(ctx, service, cli.ConfigFile(), progress.ContextWriter(ctx), false, "")
+	assert.ErrorContains(t, err, `service "app" requires platform linux/arm64, but image "registry.example.com/app:1.0" is only available for linux/amd64`)
+
+	// pull error is reported as is when available platforms can't be inspected
+	api.EXPECT().ImagePull(gomock.Any(), service.Image, gomock.Any()).Return(nil, noMatch)
+	api.EXPECT().DistributionInspect(gomock.Any(), service.Image, gomock.Any()).Return(registry.DistributionInspect{}, errors.New("unsupported"))
+	_, err = tested.pullServiceImage(ctx, service, cli.ConfigFile(), progress.ContextWriter(ctx), false, "")
+	assert.ErrorContains(t, err, "no matching manifest for linux/arm64")
+
+	// a matching platform is pulled without inspecting the registry
+	service.Platform = "linux/amd64"
+	api.EXPECT().DistributionInspect(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	api.EXPECT().ImagePull(gomock.Any(), service.Image, gomock.Any()).Return(io.NopCloser(strings.NewReader("")), nil)
+	api.EXPECT().ImageInspectWithRaw(gomock.Any(), service.Image).Return(moby.ImageInspect{ID: "sha256:app"}, nil, nil)
+	id, err := tested.pullServiceImage(ctx, service, cli.ConfigFile(), progress.ContextWriter(ctx), false, "")
+	assert.NilError(t, err)
+	assert.Equal(t, id, "sha256:app")
+}
+
+func TestEnsureImagesExistsPullPolicy(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	tested := composeService{
+		dockerCli: cli,
+	}
+	ctx := context.Background()
+	notFound := errdefs.NotFound(errors.New("no such image"))
+
+	// never pull a missing image
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"db": {Name: "db", Image: "postgres:16", PullPolicy: types.PullPolicyNever},
+		},
+	}
+	api.EXPECT().ImageInspectWithRaw(gomock.Any(), "postgres:16").Return(moby.ImageInspect{}, nil, notFound)
+	err := tested.ensureImagesExists(ctx, project, ensureImagesOptions{QuietPull: true})
+	assert.ErrorContains(t, err, `image "postgres:16" for service "db" is not available locally and pull_policy is never`)
+
+	// always pull, even if image is present
+	project.Services["db"] = types.ServiceConfig{Name: "db", Image: "postgres:16", PullPolicy: types.PullPolicyAlways, CustomLabels: types.Labels{}}
+	gomock.InOrder(
+		api.EXPECT().ImageInspectWithRaw(gomock.Any(), "postgres:16").Return(moby.ImageInspect{ID: "sha256:old"}, nil, nil),
+		api.EXPECT().ImagePull(gomock.Any(), "postgres:16", gomock.Any()).Return(io.NopCloser(strings.NewReader("")), nil),
+		api.EXPECT().ImageInspectWithRaw(gomock.Any(), "postgres:16").Return(moby.ImageInspect{ID: "sha256:new"}, nil, nil),
+	)
+	err = tested.ensureImagesExists(ctx, project, ensureImagesOptions{QuietPull: true})
+	assert.NilError(t, err)
+	assert.Equal(t, project.Services["db"].CustomLabels["com.docker.compose.image"], "sha256:new")
+
+	// build requires a build section
+	project.Services["db"] = types.ServiceConfig{Name: "db", Image: "postgres:16", PullPolicy: types.PullPolicyBuild}
+	err = tested.ensureImagesExists(ctx, project, ensureImagesOptions{QuietPull: true})
+	assert.ErrorContains(t, err, `invalid service "db". pull_policy build requires a build section`)
+}
+
+func TestEncodedAuth(t *testing.T) {
+	configFile := &configfile.ConfigFile{
+		AuthConfigs: map[string]clitypes.AuthConfig{
+			"registry.example.com":        {Username: "user", Password: "secret"},
+			"https://index.docker.io/v1/": {Username: "hubuser", Password: "hubsecret"},
+		},
+	}
+	decode := func(image string) registry.AuthConfig {
+		named, err := reference.ParseNormalizedNamed(image)
+		assert.NilError(t, err)
+		encoded, err := encodedAuth(named, configFile)
+		assert.NilError(t, err)
+		buf, err := base64.URLEncoding.DecodeString(encoded)
+		assert.NilError(t, err)
+		var auth registry.AuthConfig
+		assert.NilError(t, json.Unmarshal(buf, &auth))
+		return auth
+	}
+
+	auth := decode("registry.example.com/team/app:1.0")
+	assert.Equal(t, auth.Username, "user")
+	assert.Equal(t, auth.Password, "secret")
+
+	auth = decode("library/alpine")
+	assert.Equal(t, auth.Username, "hubuser")
+
+	auth = decode("other.example.com/app")
+	assert.Equal(t, auth.Username, "")
+}
+
+func TestPullRequiredImagesSharedImage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	tested := composeService{
+		dockerCli: cli,
+	}
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"worker":    {Name: "worker", Image: "registry.example.com/app:1.0"},
+			"scheduler": {Name: "scheduler", Image: "registry.example.com/app:1.0"},
+		},
+	}
+	api.EXPECT().ImagePull(gomock.Any(), "registry.example.com/app:1.0", gomock.Any()).
+		Return(io.NopCloser(strings.NewReader("")), nil).Times(1)
+	api.EXPECT().ImageInspectWithRaw(gomock.Any(), "registry.example.com/app:1.0").Return(moby.ImageInspect{ID: "sha256:app"}, nil, nil)
+
+	images := map[string]string{}
+	err := tested.pullRequiredImages(context.Background(), project, images, true, 0, false)
+	assert.NilError(t, err)
+	assert.Equal(t, images["registry.example.com/app:1.0"], "sha256:app")
+}
+
+func TestPullIgnoreFailures(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	tested := composeService{
+		dockerCli:      cli,
+		maxConcurrency: -1,
+	}
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"api":    {Name: "api", Image: "registry.example.com/api:1.0", Build: &types.BuildConfig{Context: "."}},
+			"worker": {Name: "worker", Image: "registry.example.com/worker:1.0", Build: &types.BuildConfig{Context: "."}},
+			"db":     {Name: "db", Image: "registry.example.com/db:1.0"},
+		},
+	}
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), gomock.Any()).
+		Return(moby.ImageInspect{}, nil, errdefs.NotFound(errors.New("no such image"))).AnyTimes()
+	apiClient.EXPECT().ImagePull(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("pull access denied")).AnyTimes()
+
+	err := tested.pull(context.Background(), project, api.PullOptions{IgnoreFailures: true})
+	assert.NilError(t, err)
+
+	err = tested.pull(context.Background(), project, api.PullOptions{})
+	assert.ErrorContains(t, err, "pull access denied")
+}
+
+func TestGetLocalImagesDigestsPlatformMismatch(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+	project := &types.Project{
+		Name:        "test",
+		Environment: types.Mapping{"DOCKER_DEFAULT_PLATFORM": "linux/arm64"},
+		Services: types.Services{
+			"app": {Name: "app", Image: "app:1.0", CustomLabels: types.Labels{}},
+			"db":  {Name: "db", Image: "db:1.0", Platform: "linux/amd64", CustomLabels: types.Labels{}},
+		},
+	}
+	amd64 := moby.ImageInspect{Os: "linux", Architecture: "amd64"}
+	app, db := amd64, amd64
+	app.ID, db.ID = "sha256:app", "sha256:db"
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "app:1.0").Return(app, nil, nil)
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "db:1.0").Return(db, nil, nil)
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "sha256:app").Return(app, nil, nil)
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "sha256:db").Return(db, nil, nil)
+
+	images, err := tested.getLocalImagesDigests(context.Background(), project, 0)
+	assert.NilError(t, err)
+	// app image is pulled for DOCKER_DEFAULT_PLATFORM, so local amd64 image doesn't qualify
+	assert.DeepEqual(t, images, map[string]string{"db:1.0": "sha256:db"})
+	assert.Check(t, mustPull(project.Services["app"], images))
+	assert.Check(t, !mustPull(project.Services["db"], images))
+	assert.Equal(t, project.Services["app"].CustomLabels[api.ImageDigestLabel], "")
+	assert.Equal(t, project.Services["db"].CustomLabels[api.ImageDigestLabel], "sha256:db")
+}
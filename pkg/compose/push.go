@@ -39,6 +39,9 @@ import (
 )
 
 func (s *composeService) Push(ctx context.Context, project *types.Project, options api.PushOptions) error {
+	if s.offline {
+		return offlineError("pushing images")
+	}
 	if options.Quiet {
 		return s.push(ctx, project, options)
 	}
@@ -60,6 +63,7 @@ func (s *composeService) push(ctx context.Context, project *types.Project, optio
 	}
 
 	w := progress.ContextWriter(ctx)
+	pushed := map[string]struct{}{}
 	for _, service := range project.Services {
 		if service.Build == nil || service.Image == "" {
 			w.Event(progress.Event{
@@ -76,6 +80,11 @@ func (s *composeService) push(ctx context.Context, project *types.Project, optio
 		}
 
 		for _, tag := range tags {
+			if _, ok := pushed[tag]; ok {
+				// same image shared by multiple services or listed again in build.tags
+				continue
+			}
+			pushed[tag] = struct{}{}
 			tag := tag
 			eg.Go(func() error {
 				err := s.pushServiceImage(ctx, tag, info, s.configFile(), w, options.Quiet)
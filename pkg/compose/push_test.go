@@ -0,0 +1,71 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/docker/api/types/system"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestPushDeduplicatesTags(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	tested := composeService{
+		dockerCli:      cli,
+		maxConcurrency: -1,
+	}
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"web": {
+				Name:  "web",
+				Image: "registry.example.com/app:1.0",
+				Build: &types.BuildConfig{
+					Context: ".",
+					Tags:    []string{"registry.example.com/app:1.0", "registry.example.com/app:latest"},
+				},
+			},
+			"worker": {
+				Name:  "worker",
+				Image: "registry.example.com/app:1.0",
+				Build: &types.BuildConfig{Context: "."},
+			},
+			"db": {Name: "db", Image: "postgres"},
+		},
+	}
+	apiClient.EXPECT().Info(gomock.Any()).Return(system.Info{}, nil)
+	apiClient.EXPECT().ImagePush(gomock.Any(), "registry.example.com/app:1.0", gomock.Any()).
+		Return(io.NopCloser(strings.NewReader("")), nil).Times(1)
+	apiClient.EXPECT().ImagePush(gomock.Any(), "registry.example.com/app:latest", gomock.Any()).
+		Return(io.NopCloser(strings.NewReader("")), nil).Times(1)
+
+	err := tested.push(context.Background(), project, api.PushOptions{})
+	assert.NilError(t, err)
+}
@@ -0,0 +1,113 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/docker/docker/errdefs"
+
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+// transientRetryDelay is the delay before retrying an operation which failed with a transient error,
+// doubled on each attempt
+var transientRetryDelay = time.Second
+
+// withTransientRetries runs fn, and as long as it fails with a transient error retries it up to retries
+// times with exponential backoff. onRetry, if set, is notified before each retry
+func withTransientRetries[T any](ctx context.Context, retries int, fn func() (T, error), onRetry func(attempt int, err error)) (T, error) {
+	return withRetries(ctx, retries, isTransientError, fn, onRetry)
+}
+
+// withRetries runs fn, and as long as it fails with an error accepted by retryable retries it up to retries
+// times with exponential backoff. onRetry, if set, is notified before each retry
+func withRetries[T any](ctx context.Context, retries int, retryable func(error) bool, fn func() (T, error), onRetry func(attempt int, err error)) (T, error) {
+	delay := transientRetryDelay
+	for attempt := 1; ; attempt++ {
+		res, err := fn()
+		if err == nil || attempt > retries || !retryable(err) {
+			return res, err
+		}
+		if onRetry != nil {
+			onRetry(attempt, err)
+		}
+		select {
+		case <-ctx.Done():
+			return res, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// retryEvent reports a retry for service on progress writer
+func retryEvent(w progress.Writer, service string, retries int) func(int, error) {
+	return func(attempt int, err error) {
+		w.Event(progress.Event{
+			ID:         service,
+			Status:     progress.Working,
+			Text:       fmt.Sprintf("Retrying (%d/%d)", attempt, retries),
+			StatusText: err.Error(),
+		})
+	}
+}
+
+// isTransientError checks if err is caused by a network failure or a server side error from engine or
+// registry, which might not happen again
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errdefs.IsUnavailable(err) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	// registry errors reported by engine while pulling are only available as messages
+	return isServerErrorMessage(err.Error())
+}
+
+func isServerErrorMessage(msg string) bool {
+	for _, s := range []string{
+		"500 Internal Server Error",
+		"502 Bad Gateway",
+		"503 Service Unavailable",
+		"504 Gateway Timeout",
+		"TLS handshake timeout",
+		"connection reset by peer",
+		"i/o timeout",
+		"unexpected EOF",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
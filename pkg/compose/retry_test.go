@@ -0,0 +1,152 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/config/configfile"
+	moby "github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+func TestIsTransientError(t *testing.T) {
+	for _, tt := range []struct {
+		err       error
+		transient bool
+	}{
+		{err: errors.New("received unexpected HTTP status: 503 Service Unavailable"), transient: true},
+		{err: WrapCategorisedComposeError(errors.New("Get \"https://registry.example.com/v2/\": net/http: TLS handshake timeout"), PullFailure), transient: true},
+		{err: fmt.Errorf("pull: %w", syscall.ECONNRESET), transient: true},
+		{err: errdefs.Unavailable(errors.New("daemon is starting")), transient: true},
+		{err: errdefs.NotFound(errors.New("manifest unknown")), transient: false},
+		{err: errors.New("pull access denied for app, repository does not exist"), transient: false},
+		{err: context.Canceled, transient: false},
+	} {
+		assert.Equal(t, isTransientError(tt.err), tt.transient, tt.err.Error())
+	}
+}
+
+func TestWithTransientRetries(t *testing.T) {
+	transientRetryDelay = time.Millisecond
+	defer func() { transientRetryDelay = time.Second }()
+
+	var calls int
+	var retried []int
+	res, err := withTransientRetries(context.Background(), 3, func() (string, error) {
+		calls++
+		if calls < 3 {
+			return "", errors.New("502 Bad Gateway")
+		}
+		return "done", nil
+	}, func(attempt int, _ error) {
+		retried = append(retried, attempt)
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, res, "done")
+	assert.DeepEqual(t, retried, []int{1, 2})
+
+	calls = 0
+	_, err = withTransientRetries(context.Background(), 3, func() (string, error) {
+		calls++
+		return "", errors.New("unauthorized: authentication required")
+	}, nil)
+	assert.ErrorContains(t, err, "unauthorized")
+	assert.Equal(t, calls, 1)
+
+	calls = 0
+	_, err = withTransientRetries(context.Background(), 2, func() (string, error) {
+		calls++
+		return "", errors.New("i/o timeout")
+	}, nil)
+	assert.ErrorContains(t, err, "i/o timeout")
+	assert.Equal(t, calls, 3)
+}
+
+func TestPullRequiredImagesRetriesTransientFailures(t *testing.T) {
+	transientRetryDelay = time.Millisecond
+	defer func() { transientRetryDelay = time.Second }()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	tested := composeService{
+		dockerCli: cli,
+	}
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {Name: "app", Image: "registry.example.com/app:1.0"},
+		},
+	}
+	gomock.InOrder(
+		apiClient.EXPECT().ImagePull(gomock.Any(), "registry.example.com/app:1.0", gomock.Any()).
+			Return(nil, errors.New("503 Service Unavailable")),
+		apiClient.EXPECT().ImagePull(gomock.Any(), "registry.example.com/app:1.0", gomock.Any()).
+			Return(io.NopCloser(strings.NewReader("")), nil),
+	)
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "registry.example.com/app:1.0").Return(moby.ImageInspect{ID: "sha256:app"}, nil, nil)
+
+	images := map[string]string{}
+	err := tested.pullRequiredImages(context.Background(), project, images, true, 1, false)
+	assert.NilError(t, err)
+	assert.Equal(t, images["registry.example.com/app:1.0"], "sha256:app")
+}
+
+func TestPullRequiredImagesRetriesNotFound(t *testing.T) {
+	transientRetryDelay = time.Millisecond
+	defer func() { transientRetryDelay = time.Second }()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	tested := composeService{
+		dockerCli: cli,
+	}
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"app": {Name: "app", Image: "registry.example.com/app:1.0"},
+		},
+	}
+	gomock.InOrder(
+		apiClient.EXPECT().ImagePull(gomock.Any(), "registry.example.com/app:1.0", gomock.Any()).
+			Return(nil, errdefs.NotFound(errors.New("manifest unknown"))),
+		apiClient.EXPECT().ImagePull(gomock.Any(), "registry.example.com/app:1.0", gomock.Any()).
+			Return(io.NopCloser(strings.NewReader("")), nil),
+	)
+	apiClient.EXPECT().ImageInspectWithRaw(gomock.Any(), "registry.example.com/app:1.0").Return(moby.ImageInspect{ID: "sha256:app"}, nil, nil)
+
+	images := map[string]string{}
+	err := tested.pullRequiredImages(context.Background(), project, images, true, 0, true)
+	assert.NilError(t, err)
+	assert.Equal(t, images["registry.example.com/app:1.0"], "sha256:app")
+}
@@ -0,0 +1,72 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestApplyRunOptions(t *testing.T) {
+	project := &types.Project{
+		Name:        "test",
+		Environment: types.Mapping{"TOKEN": "secret"},
+	}
+	service := types.ServiceConfig{
+		Name:        "app",
+		Command:     types.ShellCommand{"serve"},
+		CapAdd:      []string{"NET_ADMIN"},
+		Environment: types.NewMappingWithEquals([]string{"MODE=prod"}),
+	}
+
+	applyRunOptions(project, &service, api.RunOptions{
+		Name:        "one-off",
+		Command:     []string{"migrate"},
+		User:        "root",
+		CapDrop:     []string{"NET_ADMIN"},
+		WorkingDir:  "/src",
+		Environment: []string{"MODE=test", "TOKEN", "MISSING"},
+		Labels:      types.Labels{"com.example.task": "migration"},
+	})
+
+	assert.Equal(t, service.ContainerName, "one-off")
+	assert.DeepEqual(t, service.Command, types.ShellCommand{"migrate"})
+	assert.Equal(t, service.User, "root")
+	assert.DeepEqual(t, service.CapDrop, []string{"NET_ADMIN"})
+	assert.Equal(t, len(service.CapAdd), 0)
+	assert.Equal(t, service.WorkingDir, "/src")
+	assert.DeepEqual(t, flatten(service.Environment), types.Mapping{"MODE": "test", "TOKEN": "secret"})
+	assert.Equal(t, service.Labels["com.example.task"], "migration")
+}
+
+func TestApplyRunOptionsEntrypoint(t *testing.T) {
+	service := types.ServiceConfig{
+		Name:    "app",
+		Command: types.ShellCommand{"serve"},
+	}
+
+	applyRunOptions(&types.Project{Name: "test"}, &service, api.RunOptions{
+		Entrypoint: []string{"sh", "-c"},
+	})
+
+	assert.DeepEqual(t, service.Entrypoint, types.ShellCommand{"sh", "-c"})
+	assert.Equal(t, len(service.Command), 0)
+}
@@ -26,7 +26,11 @@ import (
 
 func (s *composeService) Scale(ctx context.Context, project *types.Project, options api.ScaleOptions) error {
 	return progress.Run(ctx, tracing.SpanWrapFunc("project/scale", tracing.ProjectOptions(ctx, project), func(ctx context.Context) error {
-		err := s.create(ctx, project, api.CreateOptions{Services: options.Services})
+		err := applyReplicas(project, options.Replicas)
+		if err != nil {
+			return err
+		}
+		err = s.create(ctx, project, api.CreateOptions{Services: options.Services})
 		if err != nil {
 			return err
 		}
@@ -34,3 +38,19 @@ func (s *composeService) Scale(ctx context.Context, project *types.Project, opti
 
 	}), s.stdinfo())
 }
+
+// applyReplicas sets the scale of the selected services
+func applyReplicas(project *types.Project, replicas map[string]int) error {
+	for name, n := range replicas {
+		if n < 0 {
+			return api.NewServiceError(name, api.ErrInvalidConfig, "invalid number of replicas for service %q: %d", name, n)
+		}
+		service, err := project.GetService(name)
+		if err != nil {
+			return err
+		}
+		service.SetScale(n)
+		project.Services[name] = service
+	}
+	return nil
+}
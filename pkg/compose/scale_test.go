@@ -0,0 +1,49 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestApplyReplicas(t *testing.T) {
+	project := &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"web":    {Name: "web", Deploy: &types.DeployConfig{}},
+			"worker": {Name: "worker"},
+		},
+	}
+
+	err := applyReplicas(project, map[string]int{"web": 3, "worker": 0})
+	assert.NilError(t, err)
+	assert.Equal(t, project.Services["web"].GetScale(), 3)
+	assert.Equal(t, *project.Services["web"].Deploy.Replicas, 3)
+	assert.Equal(t, project.Services["worker"].GetScale(), 0)
+
+	err = applyReplicas(project, map[string]int{"web": -1})
+	assert.Error(t, err, `invalid number of replicas for service "web": -1`)
+	assert.Assert(t, api.IsErrInvalidConfig(err))
+
+	err = applyReplicas(project, map[string]int{"db": 1})
+	assert.ErrorContains(t, err, "db")
+}
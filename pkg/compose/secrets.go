@@ -77,6 +77,8 @@ func (s *composeService) injectConfigs(ctx context.Context, project *types.Proje
 
 		if config.Target == "" {
 			config.Target = "/" + config.Source
+		} else if !isAbsTarget(config.Target) {
+			config.Target = "/" + config.Target
 		}
 
 		b, err := createTar(content, types.FileReferenceConfig(config))
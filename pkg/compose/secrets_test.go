@@ -0,0 +1,74 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"archive/tar"
+	"io"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestCreateTar(t *testing.T) {
+	mode := uint32(0o400)
+	b, err := createTar("s3cr3t", types.FileReferenceConfig{
+		Target: "/run/secrets/token",
+		UID:    "1000",
+		GID:    "1001",
+		Mode:   &mode,
+	})
+	assert.NilError(t, err)
+
+	r := tar.NewReader(&b)
+	header, err := r.Next()
+	assert.NilError(t, err)
+	assert.Equal(t, header.Name, "/run/secrets/token")
+	assert.Equal(t, header.Uid, 1000)
+	assert.Equal(t, header.Gid, 1001)
+	assert.Equal(t, header.Mode, int64(0o400))
+	content, err := io.ReadAll(r)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "s3cr3t")
+
+	_, err = createTar("s3cr3t", types.FileReferenceConfig{Target: "/run/secrets/token", UID: "root"})
+	assert.ErrorContains(t, err, "invalid syntax")
+}
+
+func TestBuildContainerSecretMounts(t *testing.T) {
+	project := types.Project{
+		Name: "test",
+		Secrets: types.Secrets{
+			"token":  {Name: "test_token", Environment: "TOKEN"},
+			"shared": {Name: "shared", External: true},
+		},
+	}
+
+	mounts, err := buildContainerSecretMounts(project, types.ServiceConfig{
+		Name:    "app",
+		Secrets: []types.ServiceSecretConfig{{Source: "token"}},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(mounts), 0)
+
+	_, err = buildContainerSecretMounts(project, types.ServiceConfig{
+		Name:    "app",
+		Secrets: []types.ServiceSecretConfig{{Source: "shared"}},
+	})
+	assert.Error(t, err, "unsupported external secret shared")
+}
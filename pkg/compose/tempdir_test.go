@@ -0,0 +1,56 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/buildx/build"
+	"gotest.tools/v3/assert"
+)
+
+func TestWithIgnoreFileTempDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine\n"), 0o644))
+	ignoreFile := filepath.Join(dir, "api.dockerignore")
+	assert.NilError(t, os.WriteFile(ignoreFile, []byte("secrets.env\n"), 0o644))
+	service := types.ServiceConfig{
+		Name:  "api",
+		Build: &types.BuildConfig{Context: dir},
+	}
+
+	tempDir := t.TempDir()
+	inputs, cleanup, err := withIgnoreFile(build.Inputs{ContextPath: dir}, service, ignoreFile, tempDir)
+	assert.NilError(t, err)
+	defer cleanup()
+	assert.Equal(t, filepath.Dir(filepath.Dir(inputs.DockerfilePath)), tempDir)
+	assert.Check(t, filepath.Dir(filepath.Dir(inputs.DockerfilePath)) != filepath.Clean(os.TempDir()))
+}
+
+func TestCheckTempDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, checkTempDir(dir))
+	entries, err := os.ReadDir(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 0)
+
+	err = checkTempDir(filepath.Join(dir, "missing"))
+	assert.ErrorContains(t, err, "is not writable")
+}
@@ -18,6 +18,7 @@ package compose
 
 import (
 	"context"
+	"strconv"
 	"strings"
 
 	"github.com/docker/compose/v2/pkg/api"
@@ -43,9 +44,12 @@ func (s *composeService) Top(ctx context.Context, projectName string, services [
 			if err != nil {
 				return err
 			}
+			replica, _ := strconv.Atoi(container.Labels[api.ContainerNumberLabel])
 			summary[i] = api.ContainerProcSummary{
 				ID:        container.ID,
 				Name:      getCanonicalContainerName(container),
+				Service:   container.Labels[api.ServiceLabel],
+				Replica:   replica,
 				Processes: topContent.Processes,
 				Titles:    topContent.Titles,
 			}
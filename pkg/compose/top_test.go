@@ -0,0 +1,62 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"testing"
+
+	moby "github.com/docker/docker/api/types"
+	containerType "github.com/docker/docker/api/types/container"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestTop(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	web := testContainer("web", "123", false)
+	web.Labels[api.ContainerNumberLabel] = "2"
+	db := testContainer("db", "456", false)
+	db.Labels[api.ContainerNumberLabel] = "1"
+	apiClient.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return([]moby.Container{web, db}, nil)
+	apiClient.EXPECT().ContainerTop(gomock.Any(), "123", []string{}).Return(containerType.ContainerTopOKBody{
+		Titles:    []string{"UID", "PID", "CMD"},
+		Processes: [][]string{{"root", "42", "nginx"}},
+	}, nil)
+
+	summary, err := tested.Top(context.Background(), testProject, []string{"web"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, summary, []api.ContainerProcSummary{
+		{
+			ID:        "123",
+			Name:      "123",
+			Service:   "web",
+			Replica:   2,
+			Titles:    []string{"UID", "PID", "CMD"},
+			Processes: [][]string{{"root", "42", "nginx"}},
+		},
+	})
+}
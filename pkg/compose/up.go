@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 
 	"github.com/compose-spec/compose-go/v2/types"
@@ -32,7 +33,14 @@ import (
 )
 
 func (s *composeService) Up(ctx context.Context, project *types.Project, options api.UpOptions) error { //nolint:gocyclo
-	err := progress.Run(ctx, tracing.SpanWrapFunc("project/up", tracing.ProjectOptions(ctx, project), func(ctx context.Context) error {
+	project, err := withProfiles(project, options.Create.Profiles)
+	if err != nil {
+		return err
+	}
+	if options.Start.Project != nil {
+		options.Start.Project = project
+	}
+	err = progress.Run(ctx, tracing.SpanWrapFunc("project/up", tracing.ProjectOptions(ctx, project), func(ctx context.Context) error {
 		w := progress.ContextWriter(ctx)
 		w.HasMore(options.Start.Attach == nil)
 		err := s.create(ctx, project, options.Create)
@@ -64,9 +72,11 @@ func (s *composeService) Up(ctx context.Context, project *types.Project, options
 	// we might miss a signal while setting up the second channel read
 	// (this is also why signal.Notify is used vs signal.NotifyContext)
 	signalChan := make(chan os.Signal, 2)
-	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
 	defer close(signalChan)
-	var isTerminated bool
+	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
+	// stop relaying signals before the channel gets closed
+	defer signal.Stop(signalChan)
+	var isTerminated atomic.Bool
 	printer := newLogPrinter(options.Start.Attach)
 
 	doneCh := make(chan bool)
@@ -80,7 +90,7 @@ func (s *composeService) Up(ctx context.Context, project *types.Project, options
 					Services: options.Create.Services,
 					Project:  project,
 				})
-				isTerminated = true
+				isTerminated.Store(true)
 				close(doneCh)
 				return err
 			})
@@ -138,13 +148,13 @@ func (s *composeService) Up(ctx context.Context, project *types.Project, options
 
 	// We don't use parent (cancelable) context as we manage sigterm to stop the stack
 	err = s.start(context.Background(), project.Name, options.Start, printer.HandleEvent)
-	if err != nil && !isTerminated { // Ignore error if the process is terminated
+	if err != nil && !isTerminated.Load() { // Ignore error if the process is terminated
 		return err
 	}
 
 	printer.Stop()
 
-	if !isTerminated {
+	if !isTerminated.Load() {
 		// signal for the signal-handler goroutines to stop
 		close(doneCh)
 	}
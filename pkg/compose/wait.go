@@ -18,7 +18,9 @@ package compose
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/docker/compose/v2/pkg/api"
 	"golang.org/x/sync/errgroup"
@@ -33,22 +35,34 @@ func (s *composeService) Wait(ctx context.Context, projectName string, options a
 		return 0, fmt.Errorf("no containers for project %q", projectName)
 	}
 
-	eg, waitCtx := errgroup.WithContext(ctx)
-	var statusCode int64
+	// first container to exit stops waiting for the others
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	eg, waitCtx := errgroup.WithContext(waitCtx)
+	var (
+		statusCode int64
+		exited     sync.Once
+	)
 	for _, c := range containers {
 		c := c
 		eg.Go(func() error {
-			var err error
 			resultC, errC := s.dockerCli.Client().ContainerWait(waitCtx, c.ID, "")
 
 			select {
 			case result := <-resultC:
-				fmt.Fprintf(s.dockerCli.Out(), "container %q exited with status code %d\n", c.ID, result.StatusCode)
-				statusCode = result.StatusCode
-			case err = <-errC:
+				exited.Do(func() {
+					fmt.Fprintf(s.dockerCli.Out(), "container %q exited with status code %d\n", c.ID, result.StatusCode)
+					statusCode = result.StatusCode
+					cancel()
+				})
+				return nil
+			case err := <-errC:
+				if ctx.Err() == nil && errors.Is(err, context.Canceled) {
+					// another container exited first
+					return nil
+				}
+				return err
 			}
-
-			return err
 		})
 	}
 
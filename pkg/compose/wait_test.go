@@ -0,0 +1,64 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"testing"
+
+	moby "github.com/docker/docker/api/types"
+	containerType "github.com/docker/docker/api/types/container"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestWaitReturnsOnFirstExit(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	apiClient.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return([]moby.Container{
+		testContainer("job", "123", false),
+		testContainer("server", "456", false),
+	}, nil)
+	apiClient.EXPECT().ContainerWait(gomock.Any(), "123", containerType.WaitCondition("")).
+		DoAndReturn(func(ctx context.Context, id string, condition containerType.WaitCondition) (<-chan containerType.WaitResponse, <-chan error) {
+			resultC := make(chan containerType.WaitResponse, 1)
+			resultC <- containerType.WaitResponse{StatusCode: 3}
+			return resultC, make(chan error)
+		})
+	apiClient.EXPECT().ContainerWait(gomock.Any(), "456", containerType.WaitCondition("")).
+		DoAndReturn(func(ctx context.Context, id string, condition containerType.WaitCondition) (<-chan containerType.WaitResponse, <-chan error) {
+			// keeps running until waiting is canceled
+			errC := make(chan error, 1)
+			go func() {
+				<-ctx.Done()
+				errC <- ctx.Err()
+			}()
+			return make(chan containerType.WaitResponse), errC
+		})
+
+	code, err := tested.Wait(context.Background(), testProject, api.WaitOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, code, int64(3))
+}
@@ -95,17 +95,17 @@ func (s *composeService) Watch(ctx context.Context, project *types.Project, serv
 		for _, trigger := range config.Watch {
 			if trigger.Action == types.WatchActionRebuild {
 				if service.Build == nil {
-					return fmt.Errorf("can't watch service %q with action %s without a build context", service.Name, types.WatchActionRebuild)
+					return api.NewServiceError(service.Name, api.ErrInvalidConfig, "can't watch service %q with action %s without a build context", service.Name, types.WatchActionRebuild)
 				}
 				if options.Build == nil {
-					return fmt.Errorf("--no-build is incompatible with watch action %s in service %s", types.WatchActionRebuild, service.Name)
+					return api.NewServiceError(service.Name, api.ErrInvalidConfig, "--no-build is incompatible with watch action %s in service %s", types.WatchActionRebuild, service.Name)
 				}
 			}
 		}
 
 		if len(services) > 0 && service.Build == nil {
 			// service explicitly selected for watch has no build section
-			return fmt.Errorf("can't watch service %q without a build context", service.Name)
+			return api.NewServiceError(service.Name, api.ErrInvalidConfig, "can't watch service %q without a build context", service.Name)
 		}
 
 		if len(services) == 0 && service.Build == nil {
@@ -292,7 +292,7 @@ func loadDevelopmentConfig(service types.ServiceConfig, project *types.Project)
 		}
 
 		if trigger.Action == types.WatchActionRebuild && service.Build == nil {
-			return nil, fmt.Errorf("service %s doesn't have a build section, can't apply 'rebuild' on watch", service.Name)
+			return nil, api.NewServiceError(service.Name, api.ErrInvalidConfig, "service %s doesn't have a build section, can't apply 'rebuild' on watch", service.Name)
 		}
 
 		config.Watch[i] = trigger
@@ -352,7 +352,7 @@ func batchDebounceEvents(ctx context.Context, clock clockwork.Clock, delay time.
 
 func checkIfPathAlreadyBindMounted(watchPath string, volumes []types.ServiceVolumeConfig) bool {
 	for _, volume := range volumes {
-		if volume.Bind != nil && strings.HasPrefix(watchPath, volume.Source) {
+		if volume.Bind != nil && watch.IsChild(volume.Source, watchPath) {
 			return true
 		}
 	}
@@ -71,6 +71,17 @@ func TestDebounceBatching(t *testing.T) {
 	}
 }
 
+func TestCheckIfPathAlreadyBindMounted(t *testing.T) {
+	volumes := []types.ServiceVolumeConfig{
+		{Type: types.VolumeTypeBind, Source: "/src/app", Target: "/app", Bind: &types.ServiceVolumeBind{}},
+		{Type: types.VolumeTypeVolume, Source: "/src/data", Target: "/data"},
+	}
+	require.True(t, checkIfPathAlreadyBindMounted("/src/app", volumes))
+	require.True(t, checkIfPathAlreadyBindMounted("/src/app/static", volumes))
+	require.False(t, checkIfPathAlreadyBindMounted("/src/app2", volumes))
+	require.False(t, checkIfPathAlreadyBindMounted("/src/data", volumes))
+}
+
 type testWatcher struct {
 	events chan watch.FileEvent
 	errors chan error
@@ -239,6 +239,18 @@ func (mr *MockServiceMockRecorder) MaxConcurrency(parallel any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxConcurrency", reflect.TypeOf((*MockService)(nil).MaxConcurrency), parallel)
 }
 
+// OfflineMode mocks base method.
+func (m *MockService) OfflineMode(offline bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OfflineMode", offline)
+}
+
+// OfflineMode indicates an expected call of OfflineMode.
+func (mr *MockServiceMockRecorder) OfflineMode(offline any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OfflineMode", reflect.TypeOf((*MockService)(nil).OfflineMode), offline)
+}
+
 // Pause mocks base method.
 func (m *MockService) Pause(ctx context.Context, projectName string, options api.PauseOptions) error {
 	m.ctrl.T.Helper()
@@ -269,6 +281,20 @@ func (mr *MockServiceMockRecorder) Port(ctx, projectName, service, port, options
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Port", reflect.TypeOf((*MockService)(nil).Port), ctx, projectName, service, port, options)
 }
 
+// PruneImages mocks base method.
+func (m *MockService) PruneImages(ctx context.Context, project *types.Project) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneImages", ctx, project)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PruneImages indicates an expected call of PruneImages.
+func (mr *MockServiceMockRecorder) PruneImages(ctx, project any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneImages", reflect.TypeOf((*MockService)(nil).PruneImages), ctx, project)
+}
+
 // Ps mocks base method.
 func (m *MockService) Ps(ctx context.Context, projectName string, options api.PsOptions) ([]api.ContainerSummary, error) {
 	m.ctrl.T.Helper()
@@ -454,6 +480,42 @@ func (mr *MockServiceMockRecorder) Up(ctx, project, options any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Up", reflect.TypeOf((*MockService)(nil).Up), ctx, project, options)
 }
 
+// UseBuilder mocks base method.
+func (m *MockService) UseBuilder(builder api.Builder) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UseBuilder", builder)
+}
+
+// UseBuilder indicates an expected call of UseBuilder.
+func (mr *MockServiceMockRecorder) UseBuilder(builder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseBuilder", reflect.TypeOf((*MockService)(nil).UseBuilder), builder)
+}
+
+// UseImageVerifier mocks base method.
+func (m *MockService) UseImageVerifier(verifier api.ImageVerifier) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UseImageVerifier", verifier)
+}
+
+// UseImageVerifier indicates an expected call of UseImageVerifier.
+func (mr *MockServiceMockRecorder) UseImageVerifier(verifier any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseImageVerifier", reflect.TypeOf((*MockService)(nil).UseImageVerifier), verifier)
+}
+
+// UsePrompter mocks base method.
+func (m *MockService) UsePrompter(prompter api.Prompter) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UsePrompter", prompter)
+}
+
+// UsePrompter indicates an expected call of UsePrompter.
+func (mr *MockServiceMockRecorder) UsePrompter(prompter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsePrompter", reflect.TypeOf((*MockService)(nil).UsePrompter), prompter)
+}
+
 // Viz mocks base method.
 func (m *MockService) Viz(ctx context.Context, project *types.Project, options api.VizOptions) (string, error) {
 	m.ctrl.T.Helper()
@@ -484,6 +546,20 @@ func (mr *MockServiceMockRecorder) Wait(ctx, projectName, options any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Wait", reflect.TypeOf((*MockService)(nil).Wait), ctx, projectName, options)
 }
 
+// WarmBuildCache mocks base method.
+func (m *MockService) WarmBuildCache(ctx context.Context, project *types.Project, options api.BuildOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WarmBuildCache", ctx, project, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WarmBuildCache indicates an expected call of WarmBuildCache.
+func (mr *MockServiceMockRecorder) WarmBuildCache(ctx, project, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WarmBuildCache", reflect.TypeOf((*MockService)(nil).WarmBuildCache), ctx, project, options)
+}
+
 // Watch mocks base method.
 func (m *MockService) Watch(ctx context.Context, project *types.Project, services []string, options api.WatchOptions) error {
 	m.ctrl.T.Helper()
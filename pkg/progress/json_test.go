@@ -0,0 +1,46 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package progress
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestJSONWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &jsonWriter{out: &out, done: make(chan bool)}
+	w.Event(Event{ID: "db", Text: "Pulling", Status: Working})
+	w.Event(Event{ID: "db", Text: "Pulled", Status: Done, StatusText: "Pulled"})
+	w.TailMsgf("%d images pulled", 1)
+
+	dec := json.NewDecoder(&out)
+	var messages []jsonMessage
+	for dec.More() {
+		var m jsonMessage
+		assert.NilError(t, dec.Decode(&m))
+		messages = append(messages, m)
+	}
+	assert.DeepEqual(t, messages, []jsonMessage{
+		{ID: "db", Text: "Pulling", State: "working"},
+		{ID: "db", Text: "Pulled", State: "done", Status: "Pulled"},
+		{Tail: true, Text: "1 images pulled"},
+	})
+}